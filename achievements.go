@@ -0,0 +1,215 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"image/color"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"github.com/hajimehoshi/sugoimaze/internal/game"
+	"github.com/hajimehoshi/sugoimaze/internal/lang"
+	"github.com/hajimehoshi/sugoimaze/internal/textutil"
+)
+
+type achievement struct {
+	id          string
+	title       string
+	description string
+
+	// achieved reports whether the achievement is unlocked by a run that reached the goal.
+	achieved func(difficulty game.Difficulty, speed game.Speed, stats game.Stats) bool
+}
+
+var achievements = []achievement{
+	{
+		id:          "first-goal",
+		title:       "Rooftop",
+		description: "Reach the rooftop for the first time",
+		achieved: func(difficulty game.Difficulty, speed game.Speed, stats game.Stats) bool {
+			return true
+		},
+	},
+	{
+		id:          "sugoi",
+		title:       "Truly Sugoi",
+		description: "Clear the Sugoi level",
+		achieved: func(difficulty game.Difficulty, speed game.Speed, stats game.Stats) bool {
+			return difficulty == game.LevelSugoi
+		},
+	},
+	{
+		id:          "no-one-way",
+		title:       "Two-Way Traveler",
+		description: "Clear Normal+ without one-way ladders",
+		achieved: func(difficulty game.Difficulty, speed game.Speed, stats game.Stats) bool {
+			return difficulty >= game.LevelNormal && stats.OneWayLadders == 0
+		},
+	},
+	{
+		id:          "under-par",
+		title:       "Express Elevator",
+		description: "Clear Normal+ under par",
+		achieved: func(difficulty game.Difficulty, speed game.Speed, stats game.Stats) bool {
+			return difficulty >= game.LevelNormal && stats.Ticks < parTicks(difficulty, speed)
+		},
+	},
+}
+
+// parTicks returns the par time of the difficulty at the speed.
+// The par time is scaled by the time to move a tile so that every speed has a fair par.
+func parTicks(difficulty game.Difficulty, speed game.Speed) int {
+	var seconds int
+	switch difficulty {
+	case game.LevelTutorial:
+		seconds = 30
+	case game.LevelEasy:
+		seconds = 90
+	case game.LevelNormal:
+		seconds = 180
+	case game.LevelHard:
+		seconds = 360
+	case game.LevelSugoi:
+		seconds = 900
+	default:
+		panic("not reached")
+	}
+	return seconds * ebiten.DefaultTPS * speed.TicksPerMove() / game.SpeedNormal.TicksPerMove()
+}
+
+const toastDuration = 3 * ebiten.DefaultTPS
+
+type toast struct {
+	title string
+	ticks int
+}
+
+// Achievements is the set of the unlocked achievements.
+// Newly unlocked achievements are shown as toasts.
+type Achievements struct {
+	unlocked map[string]time.Time
+	loaded   bool
+	toasts   []toast
+}
+
+func achievementsPath() (string, error) {
+	dir, err := storageDir()
+	if err != nil {
+		return "", err
+	}
+	if dir == "" {
+		return "", nil
+	}
+	return filepath.Join(dir, "achievements.json"), nil
+}
+
+func (a *Achievements) load() error {
+	if a.loaded {
+		return nil
+	}
+	a.unlocked = map[string]time.Time{}
+	a.loaded = true
+
+	path, err := achievementsPath()
+	if err != nil {
+		return err
+	}
+	if path == "" {
+		return nil
+	}
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	return json.Unmarshal(bs, &a.unlocked)
+}
+
+func (a *Achievements) save() error {
+	path, err := achievementsPath()
+	if err != nil {
+		return err
+	}
+	if path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	bs, err := json.MarshalIndent(a.unlocked, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, bs, 0644)
+}
+
+// HandleFieldEvents checks the field's events and unlocks achievements.
+func (a *Achievements) HandleFieldEvents(field *game.Field) error {
+	if err := a.load(); err != nil {
+		return err
+	}
+
+	var goal bool
+	for _, e := range field.Events() {
+		if e == game.EventGoal {
+			goal = true
+		}
+	}
+	if !goal {
+		return nil
+	}
+
+	var updated bool
+	for _, ach := range achievements {
+		if _, ok := a.unlocked[ach.id]; ok {
+			continue
+		}
+		if !ach.achieved(field.Difficulty(), field.Speed(), field.Stats()) {
+			continue
+		}
+		a.unlocked[ach.id] = time.Now()
+		a.toasts = append(a.toasts, toast{title: lang.T(ach.title) + "\n" + lang.T(ach.description)})
+		updated = true
+	}
+	if !updated {
+		return nil
+	}
+	return a.save()
+}
+
+func (a *Achievements) Update() {
+	if len(a.toasts) == 0 {
+		return
+	}
+	a.toasts[0].ticks++
+	if a.toasts[0].ticks >= toastDuration {
+		a.toasts = a.toasts[1:]
+	}
+}
+
+// Draw draws the current toast on top of the screen.
+func (a *Achievements) Draw(screen *ebiten.Image) {
+	if len(a.toasts) == 0 {
+		return
+	}
+	t := a.toasts[0]
+	const (
+		w = 300
+		h = 48
+	)
+	x := (screen.Bounds().Dx() - w) / 2
+	y := 8
+	vector.DrawFilledRect(screen, float32(x), float32(y), w, h, color.RGBA{0x20, 0x20, 0x20, 0xe0}, false)
+	vector.StrokeRect(screen, float32(x), float32(y), w, h, 1, color.RGBA{0xeb, 0xd3, 0x20, 0xff}, false)
+	textutil.PrintAt(screen, lang.T("Achievement unlocked!")+"\n"+t.title, x+4, y)
+}
@@ -0,0 +1,121 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package main
+
+import (
+	"image/color"
+	"math/rand/v2"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"github.com/hajimehoshi/sugoimaze/internal/game"
+	"github.com/hajimehoshi/sugoimaze/internal/lang"
+)
+
+const (
+	// attractIdleTicks is the duration without any input to start the demo.
+	attractIdleTicks = 10 * ebiten.DefaultTPS
+
+	// attractEndTicks is the duration to show the goal before the next demo.
+	attractEndTicks = 3 * ebiten.DefaultTPS
+)
+
+var attractBackgroundColor = color.RGBA{0, 0, 0, 0xa0}
+
+// attractDemo is a demo where a bot plays a small random building behind the title menu.
+type attractDemo struct {
+	idleTicks int
+	endTicks  int
+	loader    *levelLoader
+	field     *game.Field
+	input     *game.ScriptedInput
+}
+
+// isActive reports whether the demo is running or being prepared.
+func (a *attractDemo) isActive() bool {
+	return a.loader != nil || a.field != nil
+}
+
+func (a *attractDemo) stop() {
+	if a.loader != nil {
+		a.loader.cancel()
+		a.loader = nil
+	}
+	a.field = nil
+	a.input = nil
+	a.idleTicks = 0
+	a.endTicks = 0
+}
+
+func (a *attractDemo) start() {
+	difficulty := game.LevelTutorial + game.Difficulty(rand.IntN(2))
+	a.loader = newLevelLoader(difficulty, rand.Uint64())
+}
+
+func isAnyInputJustPressed() bool {
+	if len(inpututil.AppendJustPressedKeys(nil)) > 0 {
+		return true
+	}
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		if len(inpututil.AppendJustPressedGamepadButtons(id, nil)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *attractDemo) update(gameContext GameContext) {
+	if isAnyInputJustPressed() {
+		a.stop()
+		return
+	}
+	if !a.isActive() {
+		a.idleTicks++
+		if a.idleTicks >= attractIdleTicks {
+			a.start()
+		}
+		return
+	}
+
+	if a.field == nil {
+		d := a.loader.fieldData()
+		if d == nil {
+			return
+		}
+		a.loader = nil
+		f := game.NewFieldWithData(d)
+		plan, ok := f.Solve()
+		if !ok {
+			a.start()
+			return
+		}
+		a.field = f
+		a.input = plan.Input(f.Speed())
+	}
+
+	a.field.SetPalette(gameContext.Config().Palette)
+	a.field.Update(a.input)
+	if a.field.IsGoalReached() {
+		a.endTicks++
+		if a.endTicks >= attractEndTicks {
+			a.endTicks = 0
+			a.field = nil
+			a.input = nil
+			a.start()
+		}
+	}
+}
+
+func (a *attractDemo) draw(screen *ebiten.Image) {
+	if a.field == nil {
+		return
+	}
+	x, y := a.field.PlayerPosition()
+	a.field.DrawWorld(screen, x, y)
+	b := screen.Bounds()
+	vector.DrawFilledRect(screen, float32(b.Min.X), float32(b.Min.Y), float32(b.Dx()), float32(b.Dy()), attractBackgroundColor, false)
+	printBottom(screen, lang.T("DEMO"))
+}
@@ -0,0 +1,286 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/hajimehoshi/ebiten/v2/audio/vorbis"
+	"github.com/hajimehoshi/ebiten/v2/audio/wav"
+
+	"github.com/hajimehoshi/sugoimaze/internal/game"
+	"github.com/hajimehoshi/sugoimaze/internal/synth"
+)
+
+var (
+	//go:embed game.ogg
+	gameOgg []byte
+
+	//go:embed bgm/title.wav
+	titleWav []byte
+
+	//go:embed bgm/goal.wav
+	goalWav []byte
+)
+
+type bgmTrack struct {
+	data []byte
+	ogg  bool
+	loop bool
+
+	// adaptive indicates that the music layers are stacked on the track by the player's height.
+	adaptive bool
+}
+
+// bgmTracks is the registry of the BGM tracks.
+var bgmTracks = map[string]bgmTrack{
+	"title": {data: titleWav, loop: true},
+	"goal":  {data: goalWav},
+
+	// All the difficulties share game.ogg for now.
+	"game-tutorial": {data: gameOgg, ogg: true, loop: true, adaptive: true},
+	"game-easy":     {data: gameOgg, ogg: true, loop: true, adaptive: true},
+	"game-normal":   {data: gameOgg, ogg: true, loop: true, adaptive: true},
+	"game-hard":     {data: gameOgg, ogg: true, loop: true, adaptive: true},
+	"game-sugoi":    {data: gameOgg, ogg: true, loop: true, adaptive: true},
+}
+
+// bgmNameForDifficulty returns the name of the BGM track for the difficulty.
+func bgmNameForDifficulty(difficulty game.Difficulty) string {
+	return "game-" + strings.ToLower(difficulty.String())
+}
+
+type bgmPlayer struct {
+	player *audio.Player
+
+	// gain is the current gain by fading, from 0 to 1.
+	gain float64
+
+	// target is the gain to fade to.
+	target float64
+
+	// rewind indicates whether to rewind the player when it is faded out.
+	rewind bool
+}
+
+// bgm plays BGM tracks one by one. Switching tracks crossfades them.
+type bgm struct {
+	context *audio.Context
+	players map[string]*bgmPlayer
+	current string
+	paused  bool
+
+	// layers are the music layers for adaptive tracks: the pulse layer and the goal layer.
+	layers   []*bgmPlayer
+	adaptive bool
+
+	volume         float64
+	crossfadeTicks int
+}
+
+func (b *bgm) player(name string) (*bgmPlayer, error) {
+	if p, ok := b.players[name]; ok {
+		return p, nil
+	}
+
+	track, ok := bgmTracks[name]
+	if !ok {
+		return nil, fmt.Errorf("sugoimaze: unknown BGM name: %s", name)
+	}
+	var stream io.ReadSeeker
+	var length int64
+	if track.ogg {
+		s, err := vorbis.DecodeWithSampleRate(b.context.SampleRate(), bytes.NewReader(track.data))
+		if err != nil {
+			return nil, err
+		}
+		stream, length = s, s.Length()
+	} else {
+		s, err := wav.DecodeWithSampleRate(b.context.SampleRate(), bytes.NewReader(track.data))
+		if err != nil {
+			return nil, err
+		}
+		stream, length = s, s.Length()
+	}
+	if track.loop {
+		stream = audio.NewInfiniteLoop(stream, length)
+	}
+	player, err := b.context.NewPlayer(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.players == nil {
+		b.players = map[string]*bgmPlayer{}
+	}
+	p := &bgmPlayer{player: player}
+	b.players[name] = p
+	return p, nil
+}
+
+// play crossfades the current track to the named track.
+// If the named track is the current track, play does nothing.
+func (b *bgm) play(name string) error {
+	if b.current == name {
+		return nil
+	}
+	p, err := b.player(name)
+	if err != nil {
+		return err
+	}
+	b.fadeOutCurrent()
+	b.adaptive = bgmTracks[name].adaptive
+	if !b.adaptive {
+		for _, l := range b.layers {
+			l.target = 0
+		}
+	}
+
+	p.target = 1
+	p.rewind = false
+	if b.crossfadeTicks == 0 {
+		p.gain = 1
+	}
+	p.player.SetVolume(b.volume * p.gain)
+	if !b.paused {
+		p.player.Play()
+	}
+	b.current = name
+	return nil
+}
+
+// stop fades out the current track. The track is rewound after fading out.
+func (b *bgm) stop() {
+	b.fadeOutCurrent()
+	b.current = ""
+	b.adaptive = false
+	for _, l := range b.layers {
+		l.target = 0
+	}
+}
+
+// setHeight sets the gains of the music layers by the player's height from 0 to 1.
+// setHeight does nothing if the current track is not adaptive.
+func (b *bgm) setHeight(height float64, nearGoal bool) error {
+	if !b.adaptive {
+		return nil
+	}
+	if b.layers == nil {
+		for _, layer := range []synth.Sequence{pulseLayer, goalLayer} {
+			player, err := b.context.NewPlayer(layer.NewStream())
+			if err != nil {
+				return err
+			}
+			b.layers = append(b.layers, &bgmPlayer{player: player})
+		}
+	}
+	b.layers[0].target = height
+	b.layers[1].target = 0
+	if nearGoal {
+		b.layers[1].target = 1
+	}
+	// Keep all the layers playing even when muted so that they are in sync.
+	for _, l := range b.layers {
+		if !b.paused && !l.player.IsPlaying() {
+			l.player.Play()
+		}
+	}
+	return nil
+}
+
+// all returns the players of the tracks and the layers.
+func (b *bgm) all() []*bgmPlayer {
+	ps := make([]*bgmPlayer, 0, len(b.players)+len(b.layers))
+	for _, p := range b.players {
+		ps = append(ps, p)
+	}
+	return append(ps, b.layers...)
+}
+
+func (b *bgm) fadeOutCurrent() {
+	p, ok := b.players[b.current]
+	if !ok {
+		return
+	}
+	p.target = 0
+	p.rewind = true
+}
+
+// pause pauses all the tracks without rewinding them.
+func (b *bgm) pause() {
+	if b.paused {
+		return
+	}
+	b.paused = true
+	for _, p := range b.all() {
+		p.player.Pause()
+	}
+}
+
+// resume resumes the tracks paused by pause.
+func (b *bgm) resume() {
+	if !b.paused {
+		return
+	}
+	b.paused = false
+	for _, p := range b.all() {
+		if p.gain > 0 || p.target > 0 {
+			p.player.Play()
+		}
+	}
+}
+
+func (b *bgm) setVolume(volume float64) {
+	b.volume = volume
+	for _, p := range b.all() {
+		p.player.SetVolume(b.volume * p.gain)
+	}
+}
+
+// update advances the fades by one tick.
+func (b *bgm) update() error {
+	if b.paused {
+		return nil
+	}
+	step := 1.0
+	if b.crossfadeTicks > 0 {
+		step = 1 / float64(b.crossfadeTicks)
+	}
+	for _, p := range b.all() {
+		if p.gain < p.target {
+			p.gain = min(p.gain+step, p.target)
+		} else if p.gain > p.target {
+			p.gain = max(p.gain-step, p.target)
+		}
+		p.player.SetVolume(b.volume * p.gain)
+		// The layers keep playing even when muted while the adaptive track is played, so that they are in sync.
+		// They are paused after fading out when the adaptive track is left.
+		if b.adaptive && slices.Contains(b.layers, p) {
+			continue
+		}
+		if p.gain == 0 && p.target == 0 {
+			if p.player.IsPlaying() {
+				p.player.Pause()
+			}
+			if p.rewind {
+				if err := p.player.Rewind(); err != nil {
+					return err
+				}
+				p.rewind = false
+			}
+		}
+	}
+	return nil
+}
+
+func crossfadeTicks(millis int) int {
+	return millis * ebiten.DefaultTPS / 1000
+}
@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package main
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"github.com/hajimehoshi/sugoimaze/internal/game"
+)
+
+const (
+	cameraMinZoom   = 0.25
+	cameraMaxZoom   = 1
+	cameraPanSpeed  = 4
+	cameraZoomSpeed = 1.25
+)
+
+// camera is a free-look camera to scout the building.
+type camera struct {
+	x    float64
+	y    float64
+	zoom float64
+
+	dragging bool
+	cursorX  int
+	cursorY  int
+
+	offscreen *ebiten.Image
+}
+
+// reset snaps the camera back to the player.
+func (c *camera) reset(field *game.Field) {
+	x, y := field.PlayerPosition()
+	c.x = float64(x)
+	c.y = float64(y)
+	c.zoom = 1
+	c.dragging = false
+}
+
+func (c *camera) update(field *game.Field) {
+	v := cameraPanSpeed / c.zoom
+	if ebiten.IsKeyPressed(ebiten.KeyArrowUp) || ebiten.IsKeyPressed(ebiten.KeyW) {
+		c.y += v
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyArrowDown) || ebiten.IsKeyPressed(ebiten.KeyS) {
+		c.y -= v
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyArrowLeft) || ebiten.IsKeyPressed(ebiten.KeyA) {
+		c.x -= v
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyArrowRight) || ebiten.IsKeyPressed(ebiten.KeyD) {
+		c.x += v
+	}
+
+	// Drag the building with the mouse.
+	x, y := ebiten.CursorPosition()
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		c.dragging = true
+	}
+	if inpututil.IsMouseButtonJustReleased(ebiten.MouseButtonLeft) {
+		c.dragging = false
+	}
+	if c.dragging {
+		c.x -= float64(x-c.cursorX) / c.zoom
+		c.y += float64(y-c.cursorY) / c.zoom
+	}
+	c.cursorX, c.cursorY = x, y
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyZ) {
+		c.zoom /= cameraZoomSpeed
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyX) {
+		c.zoom *= cameraZoomSpeed
+	}
+	if _, wy := ebiten.Wheel(); wy != 0 {
+		c.zoom *= math.Pow(cameraZoomSpeed, wy)
+	}
+	c.zoom = min(max(c.zoom, cameraMinZoom), cameraMaxZoom)
+
+	w, h := field.WorldSize()
+	c.x = min(max(c.x, 0), float64(w))
+	c.y = min(max(c.y, 0), float64(h))
+}
+
+func (c *camera) draw(screen *ebiten.Image, field *game.Field) {
+	w := int(math.Ceil(float64(screen.Bounds().Dx()) / c.zoom))
+	h := int(math.Ceil(float64(screen.Bounds().Dy()) / c.zoom))
+	if c.offscreen != nil && (c.offscreen.Bounds().Dx() != w || c.offscreen.Bounds().Dy() != h) {
+		c.offscreen.Deallocate()
+		c.offscreen = nil
+	}
+	if c.offscreen == nil {
+		c.offscreen = ebiten.NewImage(w, h)
+	}
+	c.offscreen.Clear()
+	field.DrawWorld(c.offscreen, int(c.x), int(c.y))
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(c.zoom, c.zoom)
+	op.Filter = ebiten.FilterLinear
+	screen.DrawImage(c.offscreen, op)
+}
@@ -0,0 +1,45 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+// sugoimaze-leaderboard is a leaderboard HTTP service.
+// Submitted runs are verified by re-simulating their replays.
+//
+// Usage:
+//
+//	sugoimaze-leaderboard [-addr=:8080] [-data=leaderboard.json]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/hajimehoshi/sugoimaze/internal/leaderboard"
+)
+
+var (
+	flagAddr = flag.String("addr", ":8080", "address to listen on")
+	flagData = flag.String("data", "leaderboard.json", "file to persist the entries (empty to keep them only in memory)")
+)
+
+func main() {
+	flag.Parse()
+
+	store, err := leaderboard.NewStore(*flagData)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	logger := log.New(os.Stderr, "", log.LstdFlags)
+	logger.Printf("listening on %s", *flagAddr)
+	h := &leaderboard.Handler{
+		Store:  store,
+		Logger: logger,
+	}
+	if err := http.ListenAndServe(*flagAddr, h); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,51 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+// sugoimaze-server is a server for races over a LAN.
+//
+// Usage:
+//
+//	sugoimaze-server [-addr=:7777] [-players=2] [-difficulty=2]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+
+	"github.com/hajimehoshi/sugoimaze/internal/netrace"
+)
+
+var (
+	flagAddr       = flag.String("addr", ":7777", "address to listen on")
+	flagPlayers    = flag.Int("players", 2, "number of players to start a race")
+	flagDifficulty = flag.Int("difficulty", 2, "difficulty of the buildings (0: Tutorial, 1: Easy, 2: Normal, 3: Hard, 4: Sugoi)")
+)
+
+func main() {
+	flag.Parse()
+	if *flagDifficulty < 0 || *flagDifficulty > 4 {
+		fmt.Fprintf(os.Stderr, "invalid difficulty: %d\n", *flagDifficulty)
+		os.Exit(2)
+	}
+
+	l, err := net.Listen("tcp", *flagAddr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	logger := log.New(os.Stderr, "", log.LstdFlags)
+	logger.Printf("listening on %s", l.Addr())
+
+	s := &netrace.Server{
+		Players:    *flagPlayers,
+		Difficulty: *flagDifficulty,
+		Logger:     logger,
+	}
+	if err := s.Serve(l); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/hajimehoshi/sugoimaze/internal/game"
+	"github.com/hajimehoshi/sugoimaze/internal/lang"
+)
+
+const (
+	maxVolume      = 10
+	maxWindowScale = 4
+)
+
+// Config is the user's settings.
+type Config struct {
+	// BGMVolume and SEVolume are from 0 to maxVolume.
+	BGMVolume int `json:"bgmVolume"`
+	SEVolume  int `json:"seVolume"`
+
+	// BGMCrossfadeMillis is the duration to crossfade BGM tracks in milliseconds.
+	BGMCrossfadeMillis int `json:"bgmCrossfadeMillis"`
+
+	// WindowScale is the scale of the screen to the window, from 1 to maxWindowScale.
+	WindowScale int  `json:"windowScale"`
+	Fullscreen  bool `json:"fullscreen"`
+
+	Language lang.Language `json:"language"`
+	Palette  game.Palette  `json:"palette"`
+	Speed    game.Speed    `json:"speed"`
+	Controls game.Controls `json:"controls"`
+}
+
+// DefaultConfig returns the default settings.
+func DefaultConfig() *Config {
+	return &Config{
+		BGMVolume:          maxVolume,
+		SEVolume:           maxVolume,
+		BGMCrossfadeMillis: 1000,
+		WindowScale:        2,
+		Language:           lang.English,
+		Palette:            game.PaletteDefault,
+		Speed:              game.SpeedNormal,
+		Controls:           game.DefaultControls,
+	}
+}
+
+func configPath() (string, error) {
+	dir, err := storageDir()
+	if err != nil {
+		return "", err
+	}
+	if dir == "" {
+		return "", nil
+	}
+	return filepath.Join(dir, "config.json"), nil
+}
+
+// LoadConfig loads the config file.
+// LoadConfig returns the default settings if there is no config file.
+func LoadConfig() (*Config, error) {
+	c := DefaultConfig()
+	path, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+	if path == "" {
+		return c, nil
+	}
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return c, nil
+		}
+		return nil, err
+	}
+	// Unmarshal the file over the default settings so that missing items keep their default values.
+	if err := json.Unmarshal(bs, c); err != nil {
+		return nil, err
+	}
+	c.BGMVolume = min(max(c.BGMVolume, 0), maxVolume)
+	c.SEVolume = min(max(c.SEVolume, 0), maxVolume)
+	c.BGMCrossfadeMillis = max(c.BGMCrossfadeMillis, 0)
+	c.WindowScale = min(max(c.WindowScale, 1), maxWindowScale)
+	c.Language = c.Language.Next(0)
+	c.Palette = c.Palette.Next(0)
+	c.Speed = min(max(c.Speed, game.SpeedSlow), game.SpeedFast)
+	if !c.Controls.IsValid() {
+		c.Controls = game.DefaultControls
+	}
+	return c, nil
+}
+
+func (c *Config) Save() error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	if path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	bs, err := json.MarshalIndent(c, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, bs, 0644)
+}
@@ -5,76 +5,395 @@ package main
 
 import (
 	"image/color"
+	"strings"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
 
 	game "github.com/hajimehoshi/sugoimaze/internal/game"
+	"github.com/hajimehoshi/sugoimaze/internal/lang"
+	"github.com/hajimehoshi/sugoimaze/internal/leaderboard"
+	"github.com/hajimehoshi/sugoimaze/internal/textutil"
 )
 
 type GameScene struct {
-	bgmStarted bool
-	difficulty game.Difficulty
-	field      *game.Field
-	fieldCh    chan *game.Field
+	bgmStarted     bool
+	difficulty     game.Difficulty
+	field          *game.Field
+	loader         *levelLoader
+	minimapVisible bool
+	scouting       bool
+	camera         camera
+
+	seed          uint64
+	markers       []game.Marker
+	editingMarker int
+	editingNote   []rune
+
+	goalHandled bool
+
+	submissionCh     chan error
+	submissionStatus string
+
+	// coop indicates the co-op mode, where the second player operates the switches and the doors from anywhere.
+	coop    bool
+	toggles []Toggle
+
+	// saveData is the run to continue.
+	saveData *SaveData
+}
+
+func NewGameScene(difficulty game.Difficulty, seed uint64) *GameScene {
+	return &GameScene{
+		difficulty: difficulty,
+		seed:       seed,
+	}
 }
 
-func NewGameScene(difficulty game.Difficulty) *GameScene {
+// NewCoopGameScene creates a game scene for the co-op mode.
+func NewCoopGameScene(difficulty game.Difficulty, seed uint64) *GameScene {
 	return &GameScene{
 		difficulty: difficulty,
+		seed:       seed,
+		coop:       true,
+	}
+}
+
+// NewGameSceneFromSaveData creates a game scene to continue the building in the save data.
+func NewGameSceneFromSaveData(saveData *SaveData) *GameScene {
+	return &GameScene{
+		difficulty: saveData.Difficulty,
+		seed:       saveData.Seed,
+		markers:    saveData.Markers,
+		coop:       saveData.Coop,
+		saveData:   saveData,
 	}
 }
 
 func (g *GameScene) Update(gameContext GameContext) error {
 	if !g.bgmStarted && g.field != nil {
-		gameContext.PlayBGM("game")
+		if err := gameContext.PlayBGM(bgmNameForDifficulty(g.difficulty)); err != nil {
+			return err
+		}
 		g.bgmStarted = true
 	}
 
-	if g.field == nil && g.fieldCh == nil {
-		g.fieldCh = make(chan *game.Field)
-		// Wait one second at least to show the message.
-		t := time.NewTimer(time.Second)
-		go func() {
-			f := game.NewField(g.difficulty)
-			<-t.C
-			t.Stop()
-			g.fieldCh <- f
-			close(g.fieldCh)
-			g.fieldCh = nil
-		}()
-	}
-	select {
-	case f := <-g.fieldCh:
-		g.field = f
-	default:
-	}
 	if g.field == nil {
+		if g.loader == nil {
+			g.loader = newLevelLoader(g.difficulty, g.seed)
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+			g.loader.cancel()
+			gameContext.GoToTitle()
+			return nil
+		}
+		d := g.loader.fieldData()
+		if d == nil {
+			return nil
+		}
+		g.loader = nil
+		gameContext.PrefetchNextGame(g.difficulty)
+		g.field = game.NewFieldWithData(d)
+		g.field.SetMarkers(g.markers)
+		g.field.SetSpeed(gameContext.Config().Speed)
+		// The save data before the replay was saved doesn't have the replay, and the run restarts at the entrance.
+		if g.saveData != nil && len(g.saveData.Replay) > 0 {
+			g.restore(g.saveData)
+		}
+		g.saveData = nil
+		if err := g.save(); err != nil {
+			return err
+		}
+		// The ghost is only for the single player mode.
+		if !g.coop {
+			ghost, err := LoadBestRecording(g.difficulty, g.seed, g.field.Speed())
+			if err != nil {
+				return err
+			}
+			if ghost != nil {
+				g.field.SetGhost(ghost)
+			}
+		}
+	}
+	// Apply the palette every tick so that the change in the pause menu is reflected.
+	// The speed is not changed during a run, and the pause menu doesn't show it.
+	g.field.SetPalette(gameContext.Config().Palette)
+
+	if g.editingMarker != 0 {
+		return g.updateNote()
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) && !g.field.IsGoalReached() {
+		if err := g.save(); err != nil {
+			return err
+		}
+		gameContext.PushScene(&PauseScene{})
+		return nil
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyM) || inpututil.IsKeyJustPressed(ebiten.KeyTab) {
+		g.minimapVisible = !g.minimapVisible
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyC) {
+		g.scouting = !g.scouting
+		g.camera.reset(g.field)
+	}
+	if g.scouting {
+		g.camera.update(g.field)
 		return nil
 	}
 
-	g.field.Update()
+	if !g.field.IsGoalReached() {
+		if inpututil.IsKeyJustPressed(ebiten.KeyN) {
+			m := g.field.AddMarker()
+			g.editingMarker = m.Number
+			g.editingNote = []rune(m.Note)
+			return nil
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) {
+			if g.field.RemoveMarker() {
+				if err := g.save(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	g.field.Update(g.input(gameContext.Config()))
+	// Toggle the switches and the doors after Update so that the events are kept until the next Update.
+	if g.coop && !g.field.IsGoalReached() {
+		if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+			g.toggle(false)
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+			g.toggle(true)
+		}
+	}
+	if err := playFieldSEs(gameContext, g.field); err != nil {
+		return err
+	}
+	if err := gameContext.SetBGMHeight(musicHeight(g.field)); err != nil {
+		return err
+	}
+	if !g.coop {
+		if err := gameContext.Achievements().HandleFieldEvents(g.field); err != nil {
+			return err
+		}
+	}
 	if g.field.IsGoalReached() {
+		if !g.goalHandled {
+			if err := gameContext.PlayBGM("goal"); err != nil {
+				return err
+			}
+			if err := RemoveSaveData(); err != nil {
+				return err
+			}
+			if !g.coop {
+				if err := SaveBestRecording(g.difficulty, g.seed, g.field.Speed(), g.field.Recording()); err != nil {
+					return err
+				}
+				stats := g.field.Stats()
+				if err := AddRecord(g.difficulty, Record{
+					Seed:          g.seed,
+					Speed:         g.field.Speed(),
+					Ticks:         stats.Ticks,
+					Steps:         stats.Steps,
+					SwitchPresses: stats.SwitchPresses,
+					Date:          time.Now(),
+				}); err != nil {
+					return err
+				}
+				if url := gameContext.LeaderboardURL(); url != "" {
+					g.submit(url, gameContext.PlayerName())
+				}
+			}
+			g.goalHandled = true
+		}
+		select {
+		case err := <-g.submissionCh:
+			if err != nil {
+				g.submissionStatus = lang.Sprintf("Submission failed: %s", err.Error())
+			} else {
+				g.submissionStatus = lang.T("Submitted to the leaderboard")
+			}
+		default:
+		}
 		if inpututil.IsKeyJustPressed(ebiten.KeySpace) || inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
 			gameContext.GoToTitle()
 		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyR) && !g.coop {
+			gameContext.GoToGame(g.difficulty, g.seed)
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyN) {
+			gameContext.GoToNextGame(g.difficulty, g.coop)
+		}
 	}
 
 	return nil
 }
 
+// input returns the player's input from the keyboard and the gamepads.
+func (g *GameScene) input(config *Config) game.Input {
+	// In the co-op mode, the second player interacts with the keyboard.
+	if g.coop {
+		return config.Controls.MoverOnly()
+	}
+	return playerInput(config)
+}
+
+// playerInput returns the input of a single player from the keyboard with the configured controls and the gamepads.
+func playerInput(config *Config) game.Input {
+	in := game.Inputs{config.Controls}
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		if ebiten.IsStandardGamepadLayoutAvailable(id) {
+			in = append(in, game.GamepadInput{ID: id})
+		}
+	}
+	return in
+}
+
+// submit submits the run to the leaderboard service asynchronously.
+func (g *GameScene) submit(url string, name string) {
+	s := &leaderboard.Submission{
+		Name:       name,
+		Difficulty: g.difficulty,
+		Seed:       g.seed,
+		Speed:      g.field.Speed(),
+		Ticks:      g.field.Stats().Ticks,
+		Replay:     g.field.Replay(),
+	}
+	g.submissionCh = make(chan error, 1)
+	g.submissionStatus = lang.T("Submitting to the leaderboard...")
+	go func() {
+		_, err := leaderboard.Submit(url, s)
+		g.submissionCh <- err
+	}()
+}
+
+func (g *GameScene) updateNote() error {
+	g.editingNote = ebiten.AppendInputChars(g.editingNote)
+	if len(g.editingNote) > 0 && repeatingKeyPressed(ebiten.KeyBackspace) {
+		g.editingNote = g.editingNote[:len(g.editingNote)-1]
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		g.field.SetMarkerNote(g.editingMarker, string(g.editingNote))
+		g.editingMarker = 0
+		g.editingNote = nil
+		return g.save()
+	}
+	return nil
+}
+
+// toggle toggles the switches or the doors by the second player in the co-op mode.
+func (g *GameScene) toggle(doors bool) {
+	if doors {
+		g.field.ToggleDoors()
+	} else {
+		g.field.ToggleSwitches()
+	}
+	g.toggles = append(g.toggles, Toggle{
+		Tick:  g.field.Stats().Ticks,
+		Doors: doors,
+	})
+}
+
+// restore replays the run in the save data so that the player continues from where the run was saved.
+func (g *GameScene) restore(saveData *SaveData) {
+	g.field.SetSpeed(saveData.Speed)
+	toggles := saveData.Toggles
+	for _, in := range saveData.Replay {
+		g.field.Step(in)
+		for len(toggles) > 0 && toggles[0].Tick <= g.field.Stats().Ticks {
+			g.toggle(toggles[0].Doors)
+			toggles = toggles[1:]
+		}
+	}
+}
+
+func (g *GameScene) handleClosing() error {
+	if g.field == nil || g.field.IsGoalReached() {
+		return nil
+	}
+	return g.save()
+}
+
+func (g *GameScene) save() error {
+	s := &SaveData{
+		Difficulty: g.difficulty,
+		Seed:       g.seed,
+		Markers:    g.field.Markers(),
+		Coop:       g.coop,
+		Speed:      g.field.Speed(),
+		Replay:     g.field.Replay(),
+		Toggles:    g.toggles,
+	}
+	return s.Save()
+}
+
+func repeatingKeyPressed(key ebiten.Key) bool {
+	const (
+		delay    = 30
+		interval = 3
+	)
+	d := inpututil.KeyPressDuration(key)
+	if d == 1 {
+		return true
+	}
+	if d >= delay && (d-delay)%interval == 0 {
+		return true
+	}
+	return false
+}
+
 func (g *GameScene) Draw(screen *ebiten.Image) {
 	screen.Fill(color.RGBA{0, 0, 0, 255})
 
 	if g.field == nil {
-		ebitenutil.DebugPrint(screen, "Currently under construction.\nPlease wait a moment.")
+		var progress game.Progress
+		if g.loader != nil {
+			progress = g.loader.progress()
+		}
+		drawLoadingScreen(screen, progress)
 		return
 	}
-	g.field.Draw(screen)
+	if g.scouting {
+		g.camera.draw(screen, g.field)
+		g.field.DrawHUD(screen)
+	} else {
+		g.field.Draw(screen)
+	}
+	if g.minimapVisible {
+		g.field.DrawMinimap(screen)
+	}
 
+	var msg string
 	if g.field.IsGoalReached() {
-		ebitenutil.DebugPrint(screen, "\n\nGOAL!")
+		stats := g.field.Stats()
+		msg = lang.Sprintf("GOAL! Time: %s, Steps: %d, Switches: %d", game.FormatTicks(stats.Ticks), stats.Steps, stats.SwitchPresses)
+		msg += "\n" + lang.T("Space, Enter: Title  N: Next building")
+		if !g.coop {
+			msg += "\n" + lang.T("R: Race against the ghost in the same building")
+		}
+		if g.submissionStatus != "" {
+			msg += "\n" + g.submissionStatus
+		}
+	} else if g.scouting {
+		msg = lang.T("SCOUTING (C: Back, Z/X: Zoom)")
 	}
+	if g.editingMarker != 0 {
+		msg = lang.Sprintf("Note #%d: %s_\n(Enter: Done)", g.editingMarker, string(g.editingNote))
+	}
+	if msg == "" && g.coop {
+		msg = lang.T("Co-op 2P: Space: Switches, Enter: Doors")
+	}
+	if msg != "" {
+		printBottom(screen, msg)
+	}
+}
+
+// printBottom prints the message at the bottom-left corner of the screen.
+func printBottom(screen *ebiten.Image, msg string) {
+	lines := strings.Count(msg, "\n") + 1
+	textutil.PrintAt(screen, msg, 0, screen.Bounds().Dy()-textutil.LineHeight*lines)
 }
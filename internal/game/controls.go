@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package game
+
+import (
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Controls is a key assignment to operate a player.
+type Controls struct {
+	Up       []ebiten.Key `json:"up"`
+	Down     []ebiten.Key `json:"down"`
+	Left     []ebiten.Key `json:"left"`
+	Right    []ebiten.Key `json:"right"`
+	Interact []ebiten.Key `json:"interact"`
+}
+
+var (
+	// DefaultControls is for a single player.
+	DefaultControls = Controls{
+		Up:       []ebiten.Key{ebiten.KeyArrowUp, ebiten.KeyW},
+		Down:     []ebiten.Key{ebiten.KeyArrowDown, ebiten.KeyS},
+		Left:     []ebiten.Key{ebiten.KeyArrowLeft, ebiten.KeyA},
+		Right:    []ebiten.Key{ebiten.KeyArrowRight, ebiten.KeyD},
+		Interact: []ebiten.Key{ebiten.KeySpace, ebiten.KeyEnter},
+	}
+
+	// PlayerOneControls is for the first player sharing a keyboard.
+	PlayerOneControls = Controls{
+		Up:       []ebiten.Key{ebiten.KeyW},
+		Down:     []ebiten.Key{ebiten.KeyS},
+		Left:     []ebiten.Key{ebiten.KeyA},
+		Right:    []ebiten.Key{ebiten.KeyD},
+		Interact: []ebiten.Key{ebiten.KeySpace},
+	}
+
+	// PlayerTwoControls is for the second player sharing a keyboard.
+	PlayerTwoControls = Controls{
+		Up:       []ebiten.Key{ebiten.KeyArrowUp},
+		Down:     []ebiten.Key{ebiten.KeyArrowDown},
+		Left:     []ebiten.Key{ebiten.KeyArrowLeft},
+		Right:    []ebiten.Key{ebiten.KeyArrowRight},
+		Interact: []ebiten.Key{ebiten.KeyEnter},
+	}
+)
+
+// actions returns the key assignments of all the actions.
+func (c *Controls) actions() []*[]ebiten.Key {
+	return []*[]ebiten.Key{&c.Up, &c.Down, &c.Left, &c.Right, &c.Interact}
+}
+
+// IsValid reports whether every action has a key and no key is assigned to two actions.
+func (c Controls) IsValid() bool {
+	used := map[ebiten.Key]struct{}{}
+	for _, keys := range c.actions() {
+		if len(*keys) == 0 {
+			return false
+		}
+		for _, k := range *keys {
+			if _, ok := used[k]; ok {
+				return false
+			}
+			used[k] = struct{}{}
+		}
+	}
+	return true
+}
+
+// Bind assigns only the key to the action. action must be one of the fields of c.
+// Bind returns false and changes nothing if the key is assigned to another action.
+func (c *Controls) Bind(action *[]ebiten.Key, key ebiten.Key) bool {
+	for _, keys := range c.actions() {
+		if keys != action && slices.Contains(*keys, key) {
+			return false
+		}
+	}
+	*action = []ebiten.Key{key}
+	return true
+}
+
+// MoverOnly returns the controls without the interaction keys.
+// This is for a player who only moves the Gopher in the co-op mode.
+func (c Controls) MoverOnly() Controls {
+	c.Interact = nil
+	return c
+}
+
+func isAnyKeyPressed(keys []ebiten.Key) bool {
+	for _, k := range keys {
+		if ebiten.IsKeyPressed(k) {
+			return true
+		}
+	}
+	return false
+}
+
+func isAnyKeyJustPressed(keys []ebiten.Key) bool {
+	for _, k := range keys {
+		if inpututil.IsKeyJustPressed(k) {
+			return true
+		}
+	}
+	return false
+}
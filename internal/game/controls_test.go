@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package game
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestControlsIsValid(t *testing.T) {
+	testCases := []struct {
+		name     string
+		controls Controls
+		want     bool
+	}{
+		{
+			name:     "default",
+			controls: DefaultControls,
+			want:     true,
+		},
+		{
+			name:     "player one",
+			controls: PlayerOneControls,
+			want:     true,
+		},
+		{
+			name:     "player two",
+			controls: PlayerTwoControls,
+			want:     true,
+		},
+		{
+			name: "key in two actions",
+			controls: Controls{
+				Up:       []ebiten.Key{ebiten.KeyW},
+				Down:     []ebiten.Key{ebiten.KeyS},
+				Left:     []ebiten.Key{ebiten.KeyA},
+				Right:    []ebiten.Key{ebiten.KeyD},
+				Interact: []ebiten.Key{ebiten.KeyW},
+			},
+			want: false,
+		},
+		{
+			name: "action without keys",
+			controls: Controls{
+				Up:       []ebiten.Key{ebiten.KeyW},
+				Down:     []ebiten.Key{ebiten.KeyS},
+				Left:     []ebiten.Key{ebiten.KeyA},
+				Right:    []ebiten.Key{ebiten.KeyD},
+				Interact: nil,
+			},
+			want: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.controls.IsValid(); got != tc.want {
+				t.Errorf("IsValid(): got: %t, want: %t", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestControlsBind(t *testing.T) {
+	c := DefaultControls
+
+	// A free key replaces the keys of the action.
+	if !c.Bind(&c.Interact, ebiten.KeyZ) {
+		t.Errorf("Bind(Interact, Z): got: false, want: true")
+	}
+	if !slices.Equal(c.Interact, []ebiten.Key{ebiten.KeyZ}) {
+		t.Errorf("Interact: got: %v, want: [Z]", c.Interact)
+	}
+
+	// A key of the same action can be bound.
+	if !c.Bind(&c.Up, ebiten.KeyW) {
+		t.Errorf("Bind(Up, W): got: false, want: true")
+	}
+	if !slices.Equal(c.Up, []ebiten.Key{ebiten.KeyW}) {
+		t.Errorf("Up: got: %v, want: [W]", c.Up)
+	}
+
+	// A key of another action is refused.
+	if c.Bind(&c.Up, ebiten.KeyZ) {
+		t.Errorf("Bind(Up, Z): got: true, want: false")
+	}
+	if !slices.Equal(c.Up, []ebiten.Key{ebiten.KeyW}) {
+		t.Errorf("Up after the refused Bind: got: %v, want: [W]", c.Up)
+	}
+	if !c.IsValid() {
+		t.Errorf("IsValid(): got: false, want: true")
+	}
+
+	// Binding doesn't change the default controls.
+	if !slices.Equal(DefaultControls.Interact, []ebiten.Key{ebiten.KeySpace, ebiten.KeyEnter}) {
+		t.Errorf("DefaultControls.Interact: got: %v", DefaultControls.Interact)
+	}
+}
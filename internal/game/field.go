@@ -8,133 +8,129 @@ import (
 	"image"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"github.com/hajimehoshi/sugoimaze/internal/lang"
+	"github.com/hajimehoshi/sugoimaze/internal/maze"
+	"github.com/hajimehoshi/sugoimaze/internal/textutil"
 )
 
+// Field is a run in a building with the rendering and the recording for a ghost.
 type Field struct {
-	difficulty    Difficulty
-	data          *FieldData
-	playerX       int
-	playerY       int
-	dx            int
-	dy            int
-	currentDepth0 int
-	currentDepth1 int
-	goalReached   bool
+	*maze.Run
+
+	data      *FieldData
+	markers   []Marker
+	recording Recording
+	ghost     *Recording
+	rivals    []Rival
 
 	playerImage *ebiten.Image
 }
 
-func NewField(difficulty Difficulty) *Field {
+func NewField(difficulty Difficulty, seed uint64) *Field {
+	return NewFieldWithData(NewFieldData(difficulty, seed))
+}
+
+// NewFieldWithData creates a field in the given building.
+// Multiple fields can share the same FieldData.
+func NewFieldWithData(data *FieldData) *Field {
 	f := &Field{
-		difficulty: difficulty,
-		data:       NewFieldData(difficulty),
-		playerX:    1,
-		playerY:    1,
+		Run:  maze.NewRun(data.Building),
+		data: data,
 	}
-
 	f.playerImage = f.data.tilesImage.SubImage(image.Rect(1*GridSize, 0*GridSize, 2*GridSize, 1*GridSize)).(*ebiten.Image)
-
+	f.record()
 	return f
 }
 
-func (f *Field) IsGoalReached() bool {
-	return f.goalReached
+func (f *Field) record() {
+	x, y := f.PlayerPosition()
+	floor, _ := f.Floor()
+	f.recording.record(x, y, floor)
 }
 
-func (f *Field) Update() {
-	if f.goalReached {
-		return
-	}
-
-	const v = 3
-
-	if f.dx != 0 || f.dy != 0 {
-		if f.dx > 0 {
-			f.dx += v
-		} else if f.dx < 0 {
-			f.dx -= v
-		}
-		if f.dy > 0 {
-			f.dy += v
-		} else if f.dy < 0 {
-			f.dy -= v
-		}
-		if f.dx >= GridSize {
-			f.playerX++
-			f.dx = 0
-		}
-		if f.dx <= -GridSize {
-			f.playerX--
-			f.dx = 0
-		}
-		if f.dy >= GridSize {
-			f.playerY++
-			f.dy = 0
-		}
-		if f.dy <= -GridSize {
-			f.playerY--
-			f.dy = 0
-		}
-		if f.data.isGoal(f.playerX, f.playerY) {
-			f.goalReached = true
-		}
-		return
-	}
+// SetPalette changes the palette of the building.
+// The palette is shared with other fields using the same FieldData.
+func (f *Field) SetPalette(palette Palette) {
+	f.data.SetPalette(palette)
+}
 
-	prevX, prevY := f.playerX, f.playerY
-	if inpututil.IsKeyJustPressed(ebiten.KeySpace) || inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
-		if f.data.hasSwitch(prevX, prevY, f.currentDepth1) {
-			f.currentDepth0++
-			f.currentDepth0 %= f.data.depth0
-		}
-		if f.data.hasDoor(prevX, prevY, f.currentDepth0) {
-			f.currentDepth1++
-			f.currentDepth1 %= f.data.depth1
-		}
+// Update advances the field by one tick with the input.
+func (f *Field) Update(in Input) {
+	goalReached := f.IsGoalReached()
+	f.Run.Update(in)
+	if !goalReached {
+		f.record()
 	}
+}
 
-	nextX, nextY := prevX, prevY
-	if ebiten.IsKeyPressed(ebiten.KeyArrowUp) || ebiten.IsKeyPressed(ebiten.KeyW) {
-		nextY++
-	} else if ebiten.IsKeyPressed(ebiten.KeyArrowDown) || ebiten.IsKeyPressed(ebiten.KeyS) {
-		nextY--
-	} else if ebiten.IsKeyPressed(ebiten.KeyArrowLeft) || ebiten.IsKeyPressed(ebiten.KeyA) {
-		nextX--
-	} else if ebiten.IsKeyPressed(ebiten.KeyArrowRight) || ebiten.IsKeyPressed(ebiten.KeyD) {
-		nextX++
-	}
-	if !f.data.passable(nextX, nextY, prevY, f.currentDepth0, f.currentDepth1) {
-		return
-	}
-	if nextX > f.playerX {
-		f.dx = v
-	}
-	if nextX < f.playerX {
-		f.dx = -v
-	}
-	if nextY > f.playerY {
-		f.dy = v
-	}
-	if nextY < f.playerY {
-		f.dy = -v
+// Step advances the field by one tick with the input state.
+func (f *Field) Step(in InputState) {
+	goalReached := f.IsGoalReached()
+	f.Run.Step(in)
+	if !goalReached {
+		f.record()
 	}
 }
 
 func (f *Field) Draw(screen *ebiten.Image) {
-	cx := screen.Bounds().Dx() / 2
-	cy := screen.Bounds().Dy() / 3 * 2
-	offsetX := cx - (f.playerX*GridSize + f.dx)
-	offsetY := cy + (f.playerY*GridSize + f.dy)
-	f.data.Draw(screen, offsetX, offsetY, f.currentDepth0, f.currentDepth1)
+	x, y := f.PlayerPosition()
+	f.DrawWorld(screen, x, y)
+	f.DrawHUD(screen)
+}
+
+// WorldSize returns the size of the whole building in pixels.
+func (f *Field) WorldSize() (width, height int) {
+	tiles := f.data.Tiles()
+	return len(tiles[0]) * GridSize, len(tiles) * GridSize
+}
+
+// DrawWorld draws the building and the player so that the position (cameraX, cameraY) is at the camera's center.
+// screen can be a sub-image, e.g., a half of the screen.
+func (f *Field) DrawWorld(screen *ebiten.Image, cameraX, cameraY int) {
+	b := screen.Bounds()
+	cx := b.Min.X + b.Dx()/2
+	cy := b.Min.Y + b.Dy()/3*2
+	offsetX := cx - cameraX
+	offsetY := cy + cameraY
+	depth0, depth1 := f.DepthState()
+	f.data.Draw(screen, offsetX, offsetY, depth0, depth1, f.Trail())
+	f.drawGhost(screen, offsetX, offsetY)
+	f.drawRivals(screen, offsetX, offsetY)
 
 	op := &ebiten.DrawImageOptions{}
-	op.GeoM.Translate(float64(f.playerX*GridSize+f.dx), float64(-((f.playerY+1)*GridSize + f.dy)))
+	x, y := f.PlayerPosition()
+	op.GeoM.Translate(float64(x), float64(-(y + GridSize)))
 	op.GeoM.Translate(float64(offsetX), float64(offsetY))
 	screen.DrawImage(f.playerImage, op)
 
-	msg := "Difficulty: " + f.difficulty.String()
-	msg += "\n" + fmt.Sprintf("%dF / %dF", f.data.floorNumber(f.playerY), f.data.floorCount())
-	ebitenutil.DebugPrint(screen, msg)
+	for _, m := range f.markers {
+		drawMarker(screen, m, m.X*GridSize+offsetX, -(m.Y+2)*GridSize+offsetY)
+	}
+}
+
+func (f *Field) DrawHUD(screen *ebiten.Image) {
+	floor, floorCount := f.Floor()
+	msg := lang.Sprintf("Difficulty: %s", lang.T(f.Difficulty().String()))
+	msg += "\n" + lang.Sprintf("%dF / %dF", floor, floorCount)
+	msg += "\n" + lang.Sprintf("Time: %s", FormatTicks(f.Stats().Ticks))
+	if split := f.splitMessage(); split != "" {
+		msg += "\n" + split
+	}
+	if m, ok := f.CurrentMarker(); ok && m.Note != "" {
+		msg += "\n" + fmt.Sprintf("#%d: %s", m.Number, m.Note)
+	}
+	textutil.PrintAt(screen, msg, screen.Bounds().Min.X, screen.Bounds().Min.Y)
+}
+
+func (f *Field) DrawMinimap(screen *ebiten.Image) {
+	depth0, depth1 := f.DepthState()
+	f.data.drawMinimap(screen, depth0, depth1, f.VisitedRooms())
+	f.data.drawMinimapMarkers(screen, f.markers)
+
+	s := f.data.minimapScale(screen.Bounds().Dx(), screen.Bounds().Dy())
+	px, py := f.PlayerTile()
+	x, y := f.data.minimapTilePosition(screen, px, py)
+	vector.DrawFilledRect(screen, x-s/2, y-s/2, 2*s, 2*s, minimapPlayerColor, false)
 }
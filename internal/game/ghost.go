@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package game
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/hajimehoshi/sugoimaze/internal/lang"
+)
+
+// Recording is a record of a run, which is played back as a ghost.
+type Recording struct {
+	// Positions are the player's positions in pixels for each tick.
+	// Positions[0] is the position at the start.
+	Positions [][2]int `json:"positions"`
+
+	// Splits are the ticks when the player reached each floor for the first time.
+	// Splits[0] is for the first floor.
+	Splits []int `json:"splits"`
+}
+
+// Ticks returns the duration of the run in ticks.
+func (r *Recording) Ticks() int {
+	return max(len(r.Positions)-1, 0)
+}
+
+func (r *Recording) record(x, y int, floor int) {
+	for len(r.Splits) < floor {
+		r.Splits = append(r.Splits, len(r.Positions))
+	}
+	r.Positions = append(r.Positions, [2]int{x, y})
+}
+
+// Recording returns the record of the current run.
+func (f *Field) Recording() *Recording {
+	return &f.recording
+}
+
+// SetGhost sets a recording of a previous run to be played back as a ghost alongside the player.
+func (f *Field) SetGhost(ghost *Recording) {
+	f.ghost = ghost
+}
+
+func (f *Field) drawGhost(screen *ebiten.Image, offsetX, offsetY int) {
+	if f.ghost == nil || len(f.ghost.Positions) == 0 {
+		return
+	}
+	p := f.ghost.Positions[min(f.Stats().Ticks, len(f.ghost.Positions)-1)]
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(p[0]), float64(-(p[1] + GridSize)))
+	op.GeoM.Translate(float64(offsetX), float64(offsetY))
+	op.ColorScale.ScaleAlpha(0.4)
+	screen.DrawImage(f.playerImage, op)
+}
+
+// splitMessage returns the split time of the current floor compared with the ghost's.
+func (f *Field) splitMessage() string {
+	floor := len(f.recording.Splits)
+	if floor <= 1 {
+		return ""
+	}
+	t := f.recording.Splits[floor-1]
+	msg := lang.Sprintf("%dF: %s", floor, FormatTicks(t))
+	if f.ghost != nil && len(f.ghost.Splits) >= floor {
+		diff := t - f.ghost.Splits[floor-1]
+		sign := "+"
+		if diff < 0 {
+			sign = "-"
+			diff = -diff
+		}
+		msg += fmt.Sprintf(" (%s%s)", sign, FormatTicks(diff))
+	}
+	return msg
+}
+
+// FormatTicks formats ticks as seconds.
+func FormatTicks(ticks int) string {
+	tps := ebiten.TPS()
+	return fmt.Sprintf("%d.%02d", ticks/tps, ticks%tps*100/tps)
+}
+
+// Rival is another player in the same building, e.g., in a race over a network.
+type Rival struct {
+	// X and Y are the position in pixels. The Y axis points upward.
+	X int
+	Y int
+
+	Depth0 int
+	Depth1 int
+}
+
+// SetRivals sets the other players to be drawn as ghosts.
+func (f *Field) SetRivals(rivals []Rival) {
+	f.rivals = rivals
+}
+
+func (f *Field) drawRivals(screen *ebiten.Image, offsetX, offsetY int) {
+	depth0, depth1 := f.DepthState()
+	for _, r := range f.rivals {
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(float64(r.X), float64(-(r.Y + GridSize)))
+		op.GeoM.Translate(float64(offsetX), float64(offsetY))
+		// A rival in another depth is less visible.
+		if r.Depth0 == depth0 && r.Depth1 == depth1 {
+			op.ColorScale.ScaleAlpha(0.4)
+		} else {
+			op.ColorScale.ScaleAlpha(0.2)
+		}
+		screen.DrawImage(f.playerImage, op)
+	}
+}
@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package game
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+func (c Controls) Direction() InputState {
+	var in InputState
+	if isAnyKeyPressed(c.Up) {
+		in |= InputUp
+	}
+	if isAnyKeyPressed(c.Down) {
+		in |= InputDown
+	}
+	if isAnyKeyPressed(c.Left) {
+		in |= InputLeft
+	}
+	if isAnyKeyPressed(c.Right) {
+		in |= InputRight
+	}
+	return in
+}
+
+func (c Controls) IsInteractJustPressed() bool {
+	return isAnyKeyJustPressed(c.Interact)
+}
+
+// gamepadAxisThreshold is the threshold of the left stick to be regarded as held.
+const gamepadAxisThreshold = 0.5
+
+// GamepadInput is an input from a gamepad with the standard layout.
+// The D-pad or the left stick moves the player, and the bottom face button interacts.
+type GamepadInput struct {
+	ID ebiten.GamepadID
+}
+
+func (g GamepadInput) Direction() InputState {
+	var in InputState
+	x := ebiten.StandardGamepadAxisValue(g.ID, ebiten.StandardGamepadAxisLeftStickHorizontal)
+	y := ebiten.StandardGamepadAxisValue(g.ID, ebiten.StandardGamepadAxisLeftStickVertical)
+	if ebiten.IsStandardGamepadButtonPressed(g.ID, ebiten.StandardGamepadButtonLeftTop) || y <= -gamepadAxisThreshold {
+		in |= InputUp
+	}
+	if ebiten.IsStandardGamepadButtonPressed(g.ID, ebiten.StandardGamepadButtonLeftBottom) || y >= gamepadAxisThreshold {
+		in |= InputDown
+	}
+	if ebiten.IsStandardGamepadButtonPressed(g.ID, ebiten.StandardGamepadButtonLeftLeft) || x <= -gamepadAxisThreshold {
+		in |= InputLeft
+	}
+	if ebiten.IsStandardGamepadButtonPressed(g.ID, ebiten.StandardGamepadButtonLeftRight) || x >= gamepadAxisThreshold {
+		in |= InputRight
+	}
+	return in
+}
+
+func (g GamepadInput) IsInteractJustPressed() bool {
+	return inpututil.IsStandardGamepadButtonJustPressed(g.ID, ebiten.StandardGamepadButtonRightBottom)
+}
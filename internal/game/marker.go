@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package game
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"github.com/hajimehoshi/sugoimaze/internal/textutil"
+)
+
+// Marker is a numbered marker the player puts on a tile.
+type Marker struct {
+	Number int    `json:"number"`
+	X      int    `json:"x"`
+	Y      int    `json:"y"`
+	Note   string `json:"note,omitempty"`
+}
+
+var (
+	markerColor     = color.RGBA{0xff, 0x80, 0x00, 0xff}
+	markerTextColor = color.RGBA{0, 0, 0, 0xff}
+)
+
+// Markers returns the markers put on the building.
+func (f *Field) Markers() []Marker {
+	return append([]Marker{}, f.markers...)
+}
+
+// SetMarkers replaces the markers, e.g., with ones loaded from the save file.
+func (f *Field) SetMarkers(markers []Marker) {
+	f.markers = append([]Marker{}, markers...)
+}
+
+// CurrentMarker returns the marker on the player's tile.
+func (f *Field) CurrentMarker() (Marker, bool) {
+	x, y := f.PlayerTile()
+	for _, m := range f.markers {
+		if m.X == x && m.Y == y {
+			return m, true
+		}
+	}
+	return Marker{}, false
+}
+
+// AddMarker puts a new marker on the player's tile and returns it.
+// If there is already a marker on the tile, AddMarker returns the existing one.
+func (f *Field) AddMarker() Marker {
+	if m, ok := f.CurrentMarker(); ok {
+		return m
+	}
+	number := 1
+	for _, m := range f.markers {
+		number = max(number, m.Number+1)
+	}
+	x, y := f.PlayerTile()
+	m := Marker{
+		Number: number,
+		X:      x,
+		Y:      y,
+	}
+	f.markers = append(f.markers, m)
+	return m
+}
+
+// SetMarkerNote sets the note of the marker with the given number.
+func (f *Field) SetMarkerNote(number int, note string) {
+	for i := range f.markers {
+		if f.markers[i].Number == number {
+			f.markers[i].Note = note
+			return
+		}
+	}
+}
+
+// RemoveMarker removes the marker on the player's tile.
+// RemoveMarker returns false if there is no marker on the tile.
+func (f *Field) RemoveMarker() bool {
+	x, y := f.PlayerTile()
+	for i, m := range f.markers {
+		if m.X == x && m.Y == y {
+			f.markers = append(f.markers[:i], f.markers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func drawMarker(screen *ebiten.Image, m Marker, x, y int) {
+	label := fmt.Sprint(m.Number)
+	w := textutil.Width(label) + 4
+	h := textutil.LineHeight
+	bx := x + (GridSize-w)/2
+	vector.DrawFilledRect(screen, float32(bx), float32(y), float32(w), float32(h), markerColor, false)
+	vector.StrokeRect(screen, float32(bx), float32(y), float32(w), float32(h), 1, markerTextColor, false)
+	textutil.PrintAt(screen, label, bx+2, y)
+}
+
+func (f *FieldData) drawMinimapMarkers(screen *ebiten.Image, markers []Marker) {
+	s := f.minimapScale(screen.Bounds().Dx(), screen.Bounds().Dy())
+	for _, m := range markers {
+		x, y := f.minimapTilePosition(screen, m.X, m.Y)
+		vector.DrawFilledCircle(screen, x+s/2, y+s/2, max(s, 2), markerColor, false)
+	}
+}
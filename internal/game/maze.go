@@ -0,0 +1,58 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package game
+
+import (
+	"github.com/hajimehoshi/sugoimaze/internal/maze"
+)
+
+// The rules are in the package maze so that they can be used without Ebitengine.
+
+type (
+	Difficulty      = maze.Difficulty
+	Speed           = maze.Speed
+	InputState      = maze.InputState
+	Input           = maze.Input
+	SequentialInput = maze.SequentialInput
+	Inputs          = maze.Inputs
+	ReplayInput     = maze.ReplayInput
+	ScriptedInput   = maze.ScriptedInput
+	Event           = maze.Event
+	Stats           = maze.Stats
+	Progress        = maze.Progress
+)
+
+const (
+	LevelTutorial = maze.LevelTutorial
+	LevelEasy     = maze.LevelEasy
+	LevelNormal   = maze.LevelNormal
+	LevelHard     = maze.LevelHard
+	LevelSugoi    = maze.LevelSugoi
+)
+
+const (
+	SpeedSlow   = maze.SpeedSlow
+	SpeedNormal = maze.SpeedNormal
+	SpeedFast   = maze.SpeedFast
+)
+
+const (
+	InputUp       = maze.InputUp
+	InputDown     = maze.InputDown
+	InputLeft     = maze.InputLeft
+	InputRight    = maze.InputRight
+	InputInteract = maze.InputInteract
+)
+
+const (
+	EventStep         = maze.EventStep
+	EventLadder       = maze.EventLadder
+	EventOneWayLadder = maze.EventOneWayLadder
+	EventSwitch       = maze.EventSwitch
+	EventDoor         = maze.EventDoor
+	EventBlocked      = maze.EventBlocked
+	EventGoal         = maze.EventGoal
+)
+
+const GridSize = maze.GridSize
@@ -0,0 +1,132 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package game
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// paletteColors are the representative colors of the colored tiles in tiles.png.
+var paletteColors = [4]color.RGBA{
+	{0xdb, 0x41, 0x61, 0xff},
+	{0x41, 0x61, 0xfb, 0xff},
+	{0x49, 0xaa, 0x10, 0xff},
+	{0xeb, 0xd3, 0x20, 0xff},
+}
+
+var (
+	minimapBackgroundColor = color.RGBA{0, 0, 0, 0xc0}
+	minimapVisitedColor    = color.RGBA{0x30, 0x30, 0x30, 0xff}
+	minimapWallColor       = color.RGBA{0x79, 0x79, 0x79, 0xff}
+	minimapLadderColor     = color.RGBA{0xc3, 0x71, 0x00, 0xff}
+	minimapDoorColor       = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	minimapGoalColor       = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	minimapPlayerColor     = color.RGBA{0x61, 0xd3, 0xe3, 0xff}
+)
+
+const minimapMargin = 8
+
+// minimapScale returns the size of one tile on the minimap so that the whole building fits in the screen.
+func (f *FieldData) minimapScale(screenWidth, screenHeight int) float32 {
+	const maxScale = 4
+	tiles := f.Tiles()
+	sx := float32(screenWidth-4*minimapMargin) / float32(len(tiles[0]))
+	sy := float32(screenHeight-4*minimapMargin) / float32(len(tiles))
+	return min(sx, sy, maxScale)
+}
+
+func (f *FieldData) minimapTilePosition(screen *ebiten.Image, x, y int) (float32, float32) {
+	tiles := f.Tiles()
+	s := f.minimapScale(screen.Bounds().Dx(), screen.Bounds().Dy())
+	w := s * float32(len(tiles[0]))
+	h := s * float32(len(tiles))
+	originX := float32(screen.Bounds().Min.X) + (float32(screen.Bounds().Dx())-w)/2
+	originY := float32(screen.Bounds().Min.Y) + (float32(screen.Bounds().Dy())-h)/2
+	return originX + float32(x)*s, originY + h - float32(y+1)*s
+}
+
+func (f *FieldData) drawMinimap(screen *ebiten.Image, currentDepth0, currentDepth1 int, visitedRooms [][]bool) {
+	tiles := f.Tiles()
+	s := f.minimapScale(screen.Bounds().Dx(), screen.Bounds().Dy())
+
+	x0, y0 := f.minimapTilePosition(screen, 0, len(tiles)-1)
+	width := s * float32(len(tiles[0]))
+	height := s * float32(len(tiles))
+	vector.DrawFilledRect(screen, x0-minimapMargin, y0-minimapMargin, width+2*minimapMargin, height+2*minimapMargin, minimapBackgroundColor, false)
+
+	roomXGridCount, roomYGridCount := f.RoomGridSize()
+	roomCountX, roomCountY := f.RoomCount()
+	for roomY := range roomCountY {
+		for roomX := range roomCountX {
+			if !visitedRooms[roomY][roomX] {
+				continue
+			}
+			x, y := f.minimapTilePosition(screen, roomX*roomXGridCount+1, (roomY+1)*roomYGridCount)
+			vector.DrawFilledRect(screen, x, y, s*float32(roomXGridCount), s*float32(roomYGridCount), minimapVisitedColor, false)
+		}
+	}
+
+	const transparent = 0.25
+	for y := range tiles {
+		for x := range tiles[y] {
+			t := tiles[y][x]
+			dx, dy := f.minimapTilePosition(screen, x, y)
+			w := currentDepth1
+
+			if t.Walls[w] {
+				clr := minimapWallColor
+				if t.WallColors[w] != 0 {
+					c := t.WallColors[w] - 1
+					clr = f.depthColor(c)
+					// A colored wall of the current depth is open.
+					if currentDepth0 == c {
+						clr = scaleAlpha(clr, transparent)
+					}
+				}
+				vector.DrawFilledRect(screen, dx, dy, s, s, clr, false)
+			}
+			if t.Ladders[w] {
+				clr := minimapLadderColor
+				if t.LadderColors[w] != 0 {
+					c := t.LadderColors[w] - 1
+					clr = f.depthColor(c)
+					// A colored ladder of another depth is not usable.
+					if currentDepth0 != c {
+						clr = scaleAlpha(clr, transparent)
+					}
+				}
+				vector.DrawFilledRect(screen, dx+s/4, dy, s/2, s, clr, false)
+			}
+			if t.Switches[w] {
+				vector.DrawFilledRect(screen, dx, dy+s/2, s, s/2, f.depthColor(currentDepth0), false)
+			}
+			if t.Door || t.DoorUpper {
+				clr := minimapDoorColor
+				if t.DoorColor != 0 {
+					c := t.DoorColor - 1
+					clr = f.depthColor(c)
+					if currentDepth0 != c {
+						clr = scaleAlpha(clr, transparent)
+					}
+				}
+				vector.StrokeRect(screen, dx, dy, s, s, 1, clr, false)
+			}
+			if t.Goal {
+				vector.DrawFilledRect(screen, dx-s/2, dy-s/2, 2*s, 2*s, minimapGoalColor, false)
+			}
+		}
+	}
+}
+
+func scaleAlpha(clr color.RGBA, alpha float64) color.RGBA {
+	return color.RGBA{
+		R: uint8(float64(clr.R) * alpha),
+		G: uint8(float64(clr.G) * alpha),
+		B: uint8(float64(clr.B) * alpha),
+		A: uint8(float64(clr.A) * alpha),
+	}
+}
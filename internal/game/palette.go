@@ -0,0 +1,175 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package game
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Palette is a set of colors for the colored walls, ladders, doors, and switches.
+type Palette int
+
+const (
+	PaletteDefault Palette = iota
+	PaletteHighContrast
+	PaletteColorblind
+
+	paletteCount
+)
+
+func (p Palette) String() string {
+	switch p {
+	case PaletteDefault:
+		return "Default"
+	case PaletteHighContrast:
+		return "High Contrast"
+	case PaletteColorblind:
+		return "Colorblind Safe"
+	default:
+		panic("not reached")
+	}
+}
+
+// Next returns the palette next to p by delta, wrapping around.
+func (p Palette) Next(delta int) Palette {
+	return Palette(((int(p)+delta)%int(paletteCount) + int(paletteCount)) % int(paletteCount))
+}
+
+// paletteShades are the dark, base, and light colors of the colored tiles in tiles.png.
+var paletteShades = [4][3]color.RGBA{
+	{{0xb2, 0x10, 0x30, 0xff}, {0xdb, 0x41, 0x61, 0xff}, {0xff, 0x61, 0xb2, 0xff}},
+	{{0x20, 0x00, 0xb2, 0xff}, {0x41, 0x61, 0xfb, 0xff}, {0x61, 0xa2, 0xff, 0xff}},
+	{{0x38, 0x6d, 0x00, 0xff}, {0x49, 0xaa, 0x10, 0xff}, {0x71, 0xf3, 0x41, 0xff}},
+	{{0x8a, 0x8a, 0x00, 0xff}, {0xeb, 0xd3, 0x20, 0xff}, {0xff, 0xf3, 0x92, 0xff}},
+}
+
+// shades returns the shades for the depth colors, or nil if the colors in tiles.png are used as they are.
+func (p Palette) shades() *[2][3]color.RGBA {
+	switch p {
+	case PaletteHighContrast:
+		// White and orange.
+		return &[2][3]color.RGBA{
+			{{0x90, 0x90, 0x90, 0xff}, {0xff, 0xff, 0xff, 0xff}, {0xff, 0xff, 0xff, 0xff}},
+			{{0x80, 0x38, 0x00, 0xff}, {0xff, 0x70, 0x00, 0xff}, {0xff, 0xb8, 0x60, 0xff}},
+		}
+	case PaletteColorblind:
+		// Sky blue and vermilion from the Okabe-Ito palette.
+		return &[2][3]color.RGBA{
+			{{0x00, 0x50, 0x90, 0xff}, {0x56, 0xb4, 0xe9, 0xff}, {0xb0, 0xe0, 0xff, 0xff}},
+			{{0x70, 0x28, 0x00, 0xff}, {0xd5, 0x5e, 0x00, 0xff}, {0xff, 0xa0, 0x70, 0xff}},
+		}
+	default:
+		return nil
+	}
+}
+
+// hasPatterns reports whether pattern overlays are drawn on the colored tiles
+// so that the depth colors can be distinguished without relying on hues.
+func (p Palette) hasPatterns() bool {
+	return p != PaletteDefault
+}
+
+type tilesImageKey struct {
+	palette      Palette
+	colorPalette [2]int
+}
+
+var (
+	tilesImages  = map[tilesImageKey]*ebiten.Image{}
+	tilesImagesM sync.Mutex
+)
+
+// tilesImage returns tiles.png recolored for the palette.
+// colorPalette is the indices of the colors in tiles.png that are replaced with the palette's colors.
+func tilesImage(palette Palette, colorPalette [2]int) *ebiten.Image {
+	tilesImagesM.Lock()
+	defer tilesImagesM.Unlock()
+
+	key := tilesImageKey{
+		palette:      palette,
+		colorPalette: colorPalette,
+	}
+	if img, ok := tilesImages[key]; ok {
+		return img
+	}
+
+	src, err := png.Decode(bytes.NewReader(tilesPng))
+	if err != nil {
+		panic(err)
+	}
+	dst := image.NewRGBA(src.Bounds())
+	draw.Draw(dst, dst.Bounds(), src, src.Bounds().Min, draw.Src)
+
+	if shades := palette.shades(); shades != nil {
+		// The colored tiles are in the rows from 1 to 6.
+		for y := 1 * GridSize; y < 7*GridSize; y++ {
+			for x := dst.Bounds().Min.X; x < dst.Bounds().Max.X; x++ {
+				clr := dst.RGBAAt(x, y)
+				for c, idx := range colorPalette {
+					for i, s := range paletteShades[idx] {
+						if clr == s {
+							dst.SetRGBA(x, y, shades[c][i])
+						}
+					}
+				}
+			}
+		}
+	}
+
+	img := ebiten.NewImageFromImage(dst)
+	tilesImages[key] = img
+	return img
+}
+
+// depthColor returns the representative color of the depth color c.
+func (f *FieldData) depthColor(c int) color.RGBA {
+	if shades := f.palette.shades(); shades != nil {
+		return shades[c][1]
+	}
+	return paletteColors[f.colorPalette[c]]
+}
+
+// Palette returns the current palette.
+func (f *FieldData) Palette() Palette {
+	return f.palette
+}
+
+// SetPalette changes the palette.
+func (f *FieldData) SetPalette(palette Palette) {
+	if f.palette == palette {
+		return
+	}
+	f.palette = palette
+	f.loadImages()
+}
+
+// drawPattern draws the pattern of the depth color c centered at (x, y).
+// A circle is for the first color and a cross is for the second color.
+func (f *FieldData) drawPattern(screen *ebiten.Image, c int, x, y float32, alpha float64) {
+	if !f.palette.hasPatterns() {
+		return
+	}
+
+	const r = 3
+	for i, clr := range []color.RGBA{{0xff, 0xff, 0xff, 0xff}, {0, 0, 0, 0xff}} {
+		// Draw a white outline first so that the pattern is visible on both dark and light tiles.
+		width := float32(3 - i*2)
+		clr = scaleAlpha(clr, alpha)
+		switch c {
+		case 0:
+			vector.StrokeCircle(screen, x, y, r, width, clr, true)
+		case 1:
+			vector.StrokeLine(screen, x-r, y-r, x+r, y+r, width, clr, true)
+			vector.StrokeLine(screen, x-r, y+r, x+r, y-r, width, clr, true)
+		}
+	}
+}
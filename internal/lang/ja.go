@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package lang
+
+var japanese = map[string]string{
+	// Title
+	"The Sugoi Maze Building":                "すごい迷路ビル",
+	"Continue (%s)":                          "つづきから (%s)",
+	"Continue (Co-op, %s)":                   "つづきから (協力, %s)",
+	"2P Race: < %s >":                        "2P レース: < %s >",
+	"Co-op: < %s >":                          "協力プレイ: < %s >",
+	"Leaderboard":                            "ランキング",
+	"Palette: < %s >":                        "配色: < %s >",
+	"Language: < %s >":                       "言語: < %s >",
+	"Settings":                               "設定",
+	"DEMO":                                   "デモ",
+	"Online Race (%s)":                       "オンラインレース (%s)",
+	"1P: WASD, Space\n2P: Arrow keys, Enter": "1P: WASD, Space\n2P: 矢印キー, Enter",
+	"1P: Arrow keys, WASD (Move)\n2P: Space (Switches), Enter (Doors)":                                                                        "1P: 矢印キー, WASD (移動)\n2P: Space (スイッチ), Enter (ドア)",
+	"Arrows, WASD: Move  Space, Enter: Switches, etc.\nM, Tab: Minimap  C: Scout  N: Marker and note\nBackspace: Remove a marker  Esc: Pause": "矢印キー, WASD: 移動  Space, Enter: スイッチなど\nM, Tab: ミニマップ  C: 偵察  N: マーカーとメモ\nBackspace: マーカーを消す  Esc: ポーズ",
+
+	// Settings
+	"BGM Volume: < %d >":    "BGM の音量: < %d >",
+	"SE Volume: < %d >":     "効果音の音量: < %d >",
+	"Window Scale: < %dx >": "ウィンドウの倍率: < %dx >",
+	"Fullscreen: < %s >":    "フルスクリーン: < %s >",
+	"On":                    "オン",
+	"Off":                   "オフ",
+	"Speed: < %s >":         "移動速度: < %s >",
+	"The speed applies from the next building.": "移動速度は次のビルから反映されます。",
+	"Up: %s":                           "上: %s",
+	"Down: %s":                         "下: %s",
+	"Left: %s":                         "左: %s",
+	"Right: %s":                        "右: %s",
+	"Interact: %s":                     "アクション: %s",
+	"Press a key (Esc: Cancel)":        "キーを押してください (Esc: キャンセル)",
+	"%s is already used (Esc: Cancel)": "%s は使用中です (Esc: キャンセル)",
+	"Space, Enter: Change the key":     "Space, Enter: キーを変更",
+	"Reset Controls":                   "キー設定を元に戻す",
+	"Back":                             "戻る",
+	"Left, Right: Change  Space, Enter: Select": "Left, Right: 変更  Space, Enter: 決定",
+	"Esc: Back": "Esc: 戻る",
+	"Slow":      "おそい",
+	"Fast":      "はやい",
+
+	// Difficulties
+	"Tutorial": "チュートリアル",
+	"Easy":     "かんたん",
+	"Normal":   "ふつう",
+	"Hard":     "むずかしい",
+	"Sugoi":    "すごい",
+
+	// Palettes
+	"Default":         "標準",
+	"High Contrast":   "ハイコントラスト",
+	"Colorblind Safe": "色覚サポート",
+
+	// HUD
+	"Difficulty: %s": "難易度: %s",
+	"%dF / %dF":      "%d階 / %d階",
+	"Time: %s":       "タイム: %s",
+	"%dF: %s":        "%d階: %s",
+
+	// Game
+	"Currently under construction.\nPlease wait a moment.": "ただいま建設中です。\nしばらくお待ちください。",
+	"GOAL! Time: %s, Steps: %d, Switches: %d":              "ゴール! タイム: %s, 歩数: %d, スイッチ: %d",
+	"Space, Enter: Title":                                  "Space, Enter: タイトルへ",
+	"Space, Enter: Title  N: Next building":                "Space, Enter: タイトルへ  N: 次のビルへ",
+	"R: Race against the ghost in the same building":       "R: 同じビルでゴーストと競争する",
+	"SCOUTING (C: Back, Z/X: Zoom)":                        "偵察中 (C: 戻る, Z/X: ズーム)",
+	"Note #%d: %s_\n(Enter: Done)":                         "メモ #%d: %s_\n(Enter: 完了)",
+	"Co-op 2P: Space: Switches, Enter: Doors":              "協力 2P: Space: スイッチ, Enter: ドア",
+	"Submitting to the leaderboard...":                     "ランキングに送信中...",
+	"Submitted to the leaderboard":                         "ランキングに送信しました",
+	"Submission failed: %s":                                "送信に失敗しました: %s",
+
+	// 2P race
+	"1P: WASD, Space":   "1P: WASD, Space",
+	"2P: Arrows, Enter": "2P: 矢印キー, Enter",
+	"1P WINS!":          "1P の勝ち!",
+	"2P WINS!":          "2P の勝ち!",
+	"DRAW!":             "引き分け!",
+
+	// Online race
+	"Error: %s":                   "エラー: %s",
+	"Connecting to %s...":         "%s に接続中...",
+	"Waiting for players (%d/%d)": "プレイヤーを待っています (%d/%d)",
+	"Esc: Title":                  "Esc: タイトルへ",
+	"WINNER: %s":                  "勝者: %s",
+	" (You)":                      " (あなた)",
+	"GOAL!":                       "ゴール!",
+	"The connection was closed.":  "接続が切れました。",
+
+	// Leaderboard
+	"Leaderboard: < %s >":               "ランキング: < %s >",
+	"Speed: %s":                         "移動速度: %s",
+	"No records yet.":                   "まだ記録がありません。",
+	"%d. %s  %d steps  %d switches  %s": "%d. %s  %d歩  スイッチ%d回  %s",
+	"Building: < Seed %d > (%d/%d)":     "ビル: < シード %d > (%d/%d)",
+
+	// Loading
+	"Rooms: %d / %d  Branches: %d  Attempts: %d": "部屋: %d / %d  分岐: %d  試行: %d",
+
+	// Pause
+	"Paused":               "ポーズ中",
+	"Resume":               "再開",
+	"Title":                "タイトル",
+	"Esc: Resume":          "Esc: 再開",
+	"Space, Enter: Select": "Space, Enter: 決定",
+	"The building in progress can be continued\nfrom the title, where you are now.": "建設中のビルはタイトルから\n今の場所のつづきを遊べます。",
+	"Left, Right: Difficulty": "Left, Right: 難易度",
+	"Up, Down: Speed":         "Up, Down: 移動速度",
+	"Q, E: Building":          "Q, E: ビル",
+
+	// Achievements
+	"Achievement unlocked!":                 "実績解除!",
+	"Rooftop":                               "屋上",
+	"Reach the rooftop for the first time":  "はじめて屋上にたどり着く",
+	"Truly Sugoi":                           "真のすごい",
+	"Clear the Sugoi level":                 "すごいレベルをクリアする",
+	"Two-Way Traveler":                      "行って帰れる旅人",
+	"Clear Normal+ without one-way ladders": "一方通行のはしごを使わずにふつう以上をクリアする",
+	"Express Elevator":                      "急行エレベーター",
+	"Clear Normal+ under par":               "ふつう以上を目標タイム内にクリアする",
+}
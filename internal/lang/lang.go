@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+// Package lang provides the message catalogs of the game.
+//
+// Messages are identified by their English texts.
+// A message that is not in the catalog of the current language is shown in English.
+package lang
+
+import (
+	"fmt"
+)
+
+// Language is a language of the messages.
+type Language int
+
+const (
+	English Language = iota
+	Japanese
+
+	languageCount
+)
+
+// String returns the name of the language in the language itself.
+func (l Language) String() string {
+	switch l {
+	case English:
+		return "English"
+	case Japanese:
+		return "日本語"
+	default:
+		panic("not reached")
+	}
+}
+
+// Next returns the language next to l by delta, wrapping around.
+func (l Language) Next(delta int) Language {
+	return Language(((int(l)+delta)%int(languageCount) + int(languageCount)) % int(languageCount))
+}
+
+var current = English
+
+// Current returns the current language.
+func Current() Language {
+	return current
+}
+
+// SetCurrent changes the current language.
+func SetCurrent(language Language) {
+	current = language
+}
+
+var catalogs = map[Language]map[string]string{
+	Japanese: japanese,
+}
+
+// T returns the message translated into the current language.
+func T(msg string) string {
+	if t, ok := catalogs[current][msg]; ok {
+		return t
+	}
+	return msg
+}
+
+// Sprintf is like fmt.Sprintf but translates the format first.
+func Sprintf(format string, args ...any) string {
+	return fmt.Sprintf(T(format), args...)
+}
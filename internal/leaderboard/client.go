@@ -0,0 +1,41 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package leaderboard
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var httpClient = &http.Client{
+	Timeout: 10 * time.Second,
+}
+
+// Submit submits a run to the leaderboard service at baseURL, e.g., "http://localhost:8080".
+func Submit(baseURL string, s *Submission) (Entry, error) {
+	bs, err := json.Marshal(s)
+	if err != nil {
+		return Entry{}, err
+	}
+	resp, err := httpClient.Post(strings.TrimSuffix(baseURL, "/")+"/runs", "application/json", bytes.NewReader(bs))
+	if err != nil {
+		return Entry{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return Entry{}, fmt.Errorf("leaderboard: submission failed: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	var e Entry
+	if err := json.NewDecoder(resp.Body).Decode(&e); err != nil {
+		return Entry{}, err
+	}
+	return e, nil
+}
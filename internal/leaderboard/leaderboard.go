@@ -0,0 +1,143 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+// Package leaderboard implements a leaderboard HTTP service.
+//
+// A run is submitted with the building's difficulty and seed, the player's speed and the input replay.
+// The service re-simulates the replay and records the run only when the goal is really reached in the claimed ticks.
+// Runs are ranked only among ones at the same speed.
+//
+//	POST /runs                              submits a Submission and returns the recorded Entry.
+//	GET  /runs?difficulty=N&speed=V         returns the fastest entries of the difficulty.
+//	GET  /runs?difficulty=N&speed=V&seed=S  returns the fastest entries of the building.
+//
+// speed can be omitted for the normal speed.
+package leaderboard
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/hajimehoshi/sugoimaze/internal/maze"
+)
+
+// Submission is a run submitted to the leaderboard.
+type Submission struct {
+	Name       string            `json:"name"`
+	Difficulty maze.Difficulty   `json:"difficulty"`
+	Seed       uint64            `json:"seed"`
+	Speed      maze.Speed        `json:"speed,omitempty"`
+	Ticks      int               `json:"ticks"`
+	Replay     []maze.InputState `json:"replay"`
+}
+
+// Entry is a verified run in the leaderboard.
+type Entry struct {
+	Name          string          `json:"name"`
+	Difficulty    maze.Difficulty `json:"difficulty"`
+	Seed          uint64          `json:"seed"`
+	Speed         maze.Speed      `json:"speed,omitempty"`
+	Ticks         int             `json:"ticks"`
+	Steps         int             `json:"steps"`
+	SwitchPresses int             `json:"switchPresses"`
+	Date          time.Time       `json:"date"`
+}
+
+// Verify re-simulates the submitted replay and returns the entry to record.
+func Verify(s *Submission) (Entry, error) {
+	stats, err := maze.Simulate(s.Difficulty, s.Seed, s.Speed, s.Replay)
+	if err != nil {
+		return Entry{}, err
+	}
+	if stats.Ticks != s.Ticks {
+		return Entry{}, fmt.Errorf("leaderboard: the claimed ticks %d don't match the simulated ticks %d", s.Ticks, stats.Ticks)
+	}
+	return Entry{
+		Name:          s.Name,
+		Difficulty:    s.Difficulty,
+		Seed:          s.Seed,
+		Speed:         s.Speed,
+		Ticks:         stats.Ticks,
+		Steps:         stats.Steps,
+		SwitchPresses: stats.SwitchPresses,
+		Date:          time.Now(),
+	}, nil
+}
+
+// Store is a set of entries.
+type Store struct {
+	// path is the file to persist the entries. If path is empty, the entries are kept only in memory.
+	path string
+
+	entries []Entry
+	m       sync.Mutex
+}
+
+// NewStore creates a store persisted to the file at path.
+// If path is empty, the entries are kept only in memory.
+func NewStore(path string) (*Store, error) {
+	s := &Store{
+		path: path,
+	}
+	if path == "" {
+		return s, nil
+	}
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return s, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(bs, &s.entries); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Add adds an entry.
+func (s *Store) Add(e Entry) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.entries = append(s.entries, e)
+	if s.path == "" {
+		return nil
+	}
+	bs, err := json.MarshalIndent(s.entries, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, bs, 0644)
+}
+
+// Top returns the fastest n entries of the difficulty at the speed.
+// If seed is not nil, only the entries of the building are returned.
+func (s *Store) Top(difficulty maze.Difficulty, speed maze.Speed, seed *uint64, n int) []Entry {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	var entries []Entry
+	for _, e := range s.entries {
+		if e.Difficulty != difficulty {
+			continue
+		}
+		if e.Speed != speed {
+			continue
+		}
+		if seed != nil && e.Seed != *seed {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	slices.SortStableFunc(entries, func(a, b Entry) int {
+		return a.Ticks - b.Ticks
+	})
+	return entries[:min(len(entries), n)]
+}
@@ -0,0 +1,239 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package leaderboard
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hajimehoshi/sugoimaze/internal/maze"
+)
+
+// solvedSubmission returns a submission of a run that reaches the goal with the solver's plan.
+func solvedSubmission(t *testing.T, difficulty maze.Difficulty, seed uint64, speed maze.Speed) *Submission {
+	t.Helper()
+	b, err := maze.NewBuilding(context.Background(), difficulty, seed, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := maze.NewRun(b)
+	r.SetSpeed(speed)
+	plan, ok := r.Solve()
+	if !ok {
+		t.Fatalf("Solve() failed: difficulty: %v, seed: %d", difficulty, seed)
+	}
+	in := plan.Input(speed)
+	for !in.IsOver() && !r.IsGoalReached() {
+		r.Update(in)
+	}
+	if !r.IsGoalReached() {
+		t.Fatalf("the goal was not reached: difficulty: %v, seed: %d", difficulty, seed)
+	}
+	return &Submission{
+		Name:       "player",
+		Difficulty: difficulty,
+		Seed:       seed,
+		Speed:      speed,
+		Ticks:      r.Stats().Ticks,
+		Replay:     r.Replay(),
+	}
+}
+
+func TestVerify(t *testing.T) {
+	for _, speed := range []maze.Speed{maze.SpeedSlow, maze.SpeedNormal, maze.SpeedFast} {
+		s := solvedSubmission(t, maze.LevelEasy, 1, speed)
+		e, err := Verify(s)
+		if err != nil {
+			t.Errorf("speed: %v: Verify() failed: %v", speed, err)
+			continue
+		}
+		if e.Name != s.Name || e.Difficulty != s.Difficulty || e.Seed != s.Seed || e.Speed != s.Speed || e.Ticks != s.Ticks {
+			t.Errorf("speed: %v: Verify(): got: %+v, want: the submission %+v", speed, e, s)
+		}
+		if e.Steps == 0 {
+			t.Errorf("speed: %v: Verify(): Steps must not be 0", speed)
+		}
+	}
+}
+
+func TestVerifyError(t *testing.T) {
+	s := solvedSubmission(t, maze.LevelEasy, 1, maze.SpeedNormal)
+	testCases := []struct {
+		name   string
+		modify func(s *Submission)
+	}{
+		{
+			name: "fewer ticks",
+			modify: func(s *Submission) {
+				s.Ticks--
+			},
+		},
+		{
+			name: "more ticks",
+			modify: func(s *Submission) {
+				s.Ticks++
+			},
+		},
+		{
+			name: "goal not reached",
+			modify: func(s *Submission) {
+				s.Replay = s.Replay[:len(s.Replay)/2]
+				s.Ticks = len(s.Replay)
+			},
+		},
+		{
+			name: "another seed",
+			modify: func(s *Submission) {
+				s.Seed++
+			},
+		},
+		{
+			name: "another difficulty",
+			modify: func(s *Submission) {
+				s.Difficulty = maze.LevelNormal
+			},
+		},
+		{
+			name: "another speed",
+			modify: func(s *Submission) {
+				s.Speed = maze.SpeedSlow
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := *s
+			s.Replay = append([]maze.InputState{}, s.Replay...)
+			tc.modify(&s)
+			if _, err := Verify(&s); err == nil {
+				t.Errorf("Verify() must fail")
+			}
+		})
+	}
+}
+
+func newTestServer(t *testing.T) (*Store, *httptest.Server) {
+	t.Helper()
+	store, err := NewStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(&Handler{Store: store})
+	t.Cleanup(server.Close)
+	return store, server
+}
+
+func getEntries(t *testing.T, url string) []Entry {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s: status: got: %d, want: %d", url, resp.StatusCode, http.StatusOK)
+	}
+	var entries []Entry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
+
+func TestHandler(t *testing.T) {
+	_, server := newTestServer(t)
+
+	s := solvedSubmission(t, maze.LevelEasy, 1, maze.SpeedFast)
+	e, err := Submit(server.URL, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Ticks != s.Ticks {
+		t.Errorf("Submit(): Ticks: got: %d, want: %d", e.Ticks, s.Ticks)
+	}
+
+	// A rejected run is not recorded.
+	cheat := *s
+	cheat.Ticks--
+	if _, err := Submit(server.URL, &cheat); err == nil {
+		t.Errorf("Submit() with wrong ticks must fail")
+	}
+
+	testCases := []struct {
+		query string
+		want  int
+	}{
+		{query: fmt.Sprintf("difficulty=%d&speed=%d", maze.LevelEasy, maze.SpeedFast), want: 1},
+		{query: fmt.Sprintf("difficulty=%d&speed=%d&seed=1", maze.LevelEasy, maze.SpeedFast), want: 1},
+		{query: fmt.Sprintf("difficulty=%d&speed=%d&seed=2", maze.LevelEasy, maze.SpeedFast), want: 0},
+		{query: fmt.Sprintf("difficulty=%d", maze.LevelEasy), want: 0},
+		{query: fmt.Sprintf("difficulty=%d&speed=%d", maze.LevelNormal, maze.SpeedFast), want: 0},
+	}
+	for _, tc := range testCases {
+		entries := getEntries(t, server.URL+"/runs?"+tc.query)
+		if len(entries) != tc.want {
+			t.Errorf("GET /runs?%s: got: %d entries, want: %d", tc.query, len(entries), tc.want)
+			continue
+		}
+		if tc.want > 0 && (entries[0].Name != s.Name || entries[0].Ticks != s.Ticks) {
+			t.Errorf("GET /runs?%s: got: %+v, want: the submitted run", tc.query, entries[0])
+		}
+	}
+}
+
+func TestHandlerBadRequest(t *testing.T) {
+	_, server := newTestServer(t)
+
+	for _, query := range []string{"", "difficulty=x", "difficulty=1&speed=x", "difficulty=1&seed=-1"} {
+		resp, err := http.Get(server.URL + "/runs?" + query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("GET /runs?%s: status: got: %d, want: %d", query, resp.StatusCode, http.StatusBadRequest)
+		}
+	}
+
+	// A submission larger than maxSubmissionSize is rejected before it is decoded.
+	body := fmt.Sprintf(`{"name":%q}`, strings.Repeat("a", maxSubmissionSize))
+	resp, err := http.Post(server.URL+"/runs", "application/json", bytes.NewReader([]byte(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("POST /runs with %d bytes: status: got: %d, want: %d", len(body), resp.StatusCode, http.StatusBadRequest)
+	}
+}
+
+func TestHandlerMaxEntries(t *testing.T) {
+	store, server := newTestServer(t)
+
+	// Add the entries in the reverse order to check that they are sorted.
+	for i := range maxEntries + 10 {
+		if err := store.Add(Entry{
+			Difficulty: maze.LevelEasy,
+			Seed:       1,
+			Ticks:      maxEntries + 10 - i,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries := getEntries(t, fmt.Sprintf("%s/runs?difficulty=%d", server.URL, maze.LevelEasy))
+	if len(entries) != maxEntries {
+		t.Fatalf("GET /runs: got: %d entries, want: %d", len(entries), maxEntries)
+	}
+	for i, e := range entries {
+		if e.Ticks != i+1 {
+			t.Errorf("entries[%d].Ticks: got: %d, want: %d", i, e.Ticks, i+1)
+		}
+	}
+}
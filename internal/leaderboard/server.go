@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package leaderboard
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/hajimehoshi/sugoimaze/internal/maze"
+)
+
+const (
+	maxSubmissionSize = 1 << 20
+	maxEntries        = 100
+)
+
+// Handler is an HTTP handler of the leaderboard service.
+type Handler struct {
+	Store *Store
+
+	// Logger is used to log events.
+	// If Logger is nil, the events are not logged.
+	Logger *log.Logger
+}
+
+func (h *Handler) logf(format string, args ...any) {
+	if h.Logger == nil {
+		return
+	}
+	h.Logger.Printf(format, args...)
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/runs" {
+		http.NotFound(w, r)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		h.serveEntries(w, r)
+	case http.MethodPost:
+		h.serveSubmission(w, r)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *Handler) serveEntries(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	difficulty, err := strconv.Atoi(q.Get("difficulty"))
+	if err != nil {
+		http.Error(w, "invalid difficulty", http.StatusBadRequest)
+		return
+	}
+	var speed int
+	if q.Has("speed") {
+		speed, err = strconv.Atoi(q.Get("speed"))
+		if err != nil {
+			http.Error(w, "invalid speed", http.StatusBadRequest)
+			return
+		}
+	}
+	var seed *uint64
+	if q.Has("seed") {
+		s, err := strconv.ParseUint(q.Get("seed"), 10, 64)
+		if err != nil {
+			http.Error(w, "invalid seed", http.StatusBadRequest)
+			return
+		}
+		seed = &s
+	}
+	entries := h.Store.Top(maze.Difficulty(difficulty), maze.Speed(speed), seed, maxEntries)
+	if entries == nil {
+		entries = []Entry{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(entries)
+}
+
+func (h *Handler) serveSubmission(w http.ResponseWriter, r *http.Request) {
+	var s Submission
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSubmissionSize)).Decode(&s); err != nil {
+		http.Error(w, "invalid submission: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	e, err := Verify(&s)
+	if err != nil {
+		h.logf("rejected a run by %q: %v", s.Name, err)
+		http.Error(w, "rejected: "+err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if err := h.Store.Add(e); err != nil {
+		h.logf("failed to store a run: %v", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	h.logf("recorded a run by %q: %s, seed %d, %d ticks", e.Name, e.Difficulty, e.Seed, e.Ticks)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(e)
+}
@@ -0,0 +1,944 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+// Package maze implements the rules of the game without any rendering,
+// so that a run can be simulated on a server.
+package maze
+
+import (
+	"context"
+	"math/rand/v2"
+)
+
+type Difficulty int
+
+const (
+	LevelTutorial Difficulty = iota
+	LevelEasy
+	LevelNormal
+	LevelHard
+	LevelSugoi
+)
+
+func (d Difficulty) String() string {
+	switch d {
+	case LevelTutorial:
+		return "Tutorial"
+	case LevelEasy:
+		return "Easy"
+	case LevelNormal:
+		return "Normal"
+	case LevelHard:
+		return "Hard"
+	case LevelSugoi:
+		return "Sugoi"
+	default:
+		panic("not reached")
+	}
+}
+
+type passage int
+
+const (
+	passageWall passage = iota
+	passagePassable
+	passageOneWayForward
+	passageOneWayBackward
+)
+
+type room struct {
+	passageX passage
+	passageY passage
+	passageZ passage
+	passageW passage
+	progress int
+}
+
+// Tile is a grid of a building.
+// Each slice has an element for each state of the doors (depth1).
+type Tile struct {
+	Walls     []bool
+	Ladders   []bool
+	Upward    bool
+	Downward  bool
+	Switches  []bool
+	Door      bool
+	DoorUpper bool
+	Goal      bool
+
+	// 0 is no color. 1 and more is depth+1.
+	WallColors   []int
+	LadderColors []int
+	DoorColor    int
+}
+
+// Building is a generated building and the rules in it.
+type Building struct {
+	difficulty Difficulty
+
+	width  int
+	height int
+	depth0 int
+	depth1 int
+	startX int
+	startY int
+	startZ int
+	startW int
+	goalX  int
+	goalY  int
+	goalZ  int
+	goalW  int
+
+	seed   uint64
+	random *rand.Rand
+
+	tiles [][]Tile
+}
+
+// NewBuilding generates a building and reports the progress to the callback on the same goroutine.
+// The same difficulty and seed always generate the same building.
+// progress can be nil.
+// NewBuilding returns ctx's error if ctx is canceled during the generation.
+func NewBuilding(ctx context.Context, difficulty Difficulty, seed uint64, progress func(Progress)) (*Building, error) {
+	var width int
+	var height int
+	var depth0 int
+	var depth1 int
+
+	switch difficulty {
+	case LevelTutorial:
+		width = 2
+		height = 2
+		depth0 = 2
+		depth1 = 1
+	case LevelEasy:
+		width = 5
+		height = 5
+		depth0 = 2
+		depth1 = 1
+	case LevelNormal:
+		width = 8
+		height = 8
+		depth0 = 2
+		depth1 = 1
+	case LevelHard:
+		width = 11
+		height = 11
+		depth0 = 2
+		depth1 = 1
+	case LevelSugoi:
+		width = 14
+		height = 14
+		depth0 = 2
+		depth1 = 2
+	default:
+		panic("not reached")
+	}
+
+	b := &Building{
+		width:  width,
+		height: height,
+		depth0: depth0,
+		depth1: depth1,
+		startX: 0,
+		startY: 0,
+		startZ: 0,
+		startW: 0,
+		goalX:  width - 1,
+		goalY:  height - 1,
+		goalZ:  depth0 - 1,
+		goalW:  depth1 - 1,
+		seed:   seed,
+		random: rand.New(rand.NewPCG(seed, seed)),
+	}
+	b.difficulty = difficulty
+
+	var rooms [][][][]room
+	var attempts int
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		attempts++
+		report := func(rooms [][][][]room, branches int) {
+			if progress == nil {
+				return
+			}
+			progress(Progress{
+				Attempts:      attempts,
+				VisitedRooms:  b.visitedRoomCount(rooms),
+				RequiredRooms: b.requiredVisitedRoomCount(),
+				Branches:      branches,
+			})
+		}
+		r, err := b.generateRooms(ctx, report)
+		if err != nil {
+			return nil, err
+		}
+		if r != nil {
+			rooms = r
+			break
+		}
+	}
+	b.setTiles(rooms)
+
+	return b, nil
+}
+
+// generateRooms generates the rooms, or returns nil if it fails.
+// report is called every time the correct path or a branch is added.
+func (b *Building) generateRooms(ctx context.Context, report func(rooms [][][][]room, branches int)) ([][][][]room, error) {
+	rooms := make([][][][]room, b.depth1)
+	for w := range b.depth1 {
+		rooms[w] = make([][][]room, b.depth0)
+		for z := range b.depth0 {
+			rooms[w][z] = make([][]room, b.height)
+			for y := 0; y < b.height; y++ {
+				rooms[w][z][y] = make([]room, b.width)
+			}
+		}
+	}
+
+	// Generate the correct path.
+	x, y, z, w := b.startX, b.startY, b.startZ, b.startW
+	rooms[w][z][y][x].progress = 1
+	newRooms := b.tryAddPathWithOneWay(rooms, x, y, z, w, func(x, y, z, w int, rooms [][][][]room, count int) bool {
+		return x == b.goalX && y == b.goalY && z == b.goalZ && w == b.goalW
+	})
+	if newRooms == nil {
+		return nil, nil
+	}
+	rooms = newRooms
+	rooms[b.goalW][b.goalZ][b.goalY][b.goalX].passageY = passagePassable
+	report(rooms, 0)
+
+	// Add branches.
+	var count int
+	var branches int
+	for !b.areEnoughRoomsVisited(rooms) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		var startX, startY, startZ, startW int
+		for {
+			startX, startY, startZ, startW = b.random.IntN(b.width), b.random.IntN(b.height), b.random.IntN(b.depth0), b.random.IntN(b.depth1)
+			if rooms[startW][startZ][startY][startX].progress != 0 {
+				break
+			}
+		}
+		startCount := rooms[startW][startZ][startY][startX].progress
+		newRooms := b.tryAddPathWithOneWay(rooms, startX, startY, startZ, startW, func(x, y, z, w int, rooms [][][][]room, count int) bool {
+			if rooms[w][z][y][x].progress == 0 {
+				return false
+			}
+			// A branch must not be a shortcut.
+			// Also, a good branch should go back to a position close to the start position.
+			// Multiply a constant to make better branches.
+			if startCount <= rooms[w][z][y][x].progress*5/4 {
+				return false
+			}
+			return true
+		})
+		if newRooms == nil {
+			count++
+			if count > 1000 {
+				return nil, nil
+			}
+			continue
+		}
+		rooms = newRooms
+		count = 0
+		branches++
+		report(rooms, branches)
+	}
+
+	return rooms, nil
+}
+
+func (b *Building) tryAddPathWithOneWay(rooms [][][][]room, x, y, z, w int, isGoal func(x, y, z, w int, rooms [][][][]room, count int) bool) [][][][]room {
+	// Clone rooms.
+	origRooms := rooms
+	rooms = make([][][][]room, len(origRooms))
+	for w := range b.depth1 {
+		rooms[w] = make([][][]room, len(origRooms[w]))
+		for z := range b.depth0 {
+			rooms[w][z] = make([][]room, len(origRooms[w][z]))
+			for y := range b.height {
+				rooms[w][z][y] = append([]room{}, origRooms[w][z][y]...)
+			}
+		}
+	}
+
+	var oneWayExists bool
+
+	count := rooms[w][z][y][x].progress
+
+	for !isGoal(x, y, z, w, rooms, count) {
+		var goalReached bool
+		var nextX, nextY, nextZ, nextW int
+		var oneWay bool
+		var found bool
+
+	retry:
+		for range 100 {
+			origX, origY, origZ, origW := x, y, z, w
+			nextX, nextY, nextZ, nextW = x, y, z, w
+			oneWay = false
+
+			switch d := b.random.IntN(12 + (b.depth0 - 1) + (b.depth1 - 1)); d {
+			case 0, 1, 2:
+				if nextX <= 0 {
+					continue
+				}
+				nextX--
+			case 3, 4, 5:
+				if nextX >= b.width-1 {
+					continue
+				}
+				nextX++
+			case 6, 7, 8:
+				if nextY <= 0 {
+					continue
+				}
+				nextY--
+			case 9, 10, 11:
+				if nextY >= b.height-1 {
+					continue
+				}
+				nextY++
+			case 12:
+				nextZ = (nextZ + 1) % b.depth0
+			case 13:
+				nextW = (nextW + 1) % b.depth1
+			}
+
+			// visited indicates whether the next room is already visited.
+			var visited bool
+			switch {
+			case origZ != nextZ:
+				for z := range b.depth0 {
+					if z == origZ {
+						continue
+					}
+					if rooms[nextW][nextZ][nextY][nextX].progress != 0 {
+						visited = true
+						break
+					}
+				}
+			case origW != nextW:
+				for w := range b.depth1 {
+					if w == origW {
+						continue
+					}
+					if rooms[nextW][nextZ][nextY][nextX].progress != 0 {
+						visited = true
+						break
+					}
+				}
+			case origY != nextY:
+				allWall := true
+				allWallOrOneWay := true
+				for z := range b.depth0 {
+					if origY < nextY {
+						// There is a conflicted one-way passage.
+						if rooms[origW][z][origY][origX].passageY == passageOneWayBackward {
+							continue retry
+						}
+						if rooms[origW][z][origY][origX].passageY != passageWall {
+							allWall = false
+							if rooms[origW][z][origY][origX].passageY != passageOneWayForward {
+								allWallOrOneWay = false
+							}
+						}
+					}
+					if origY > nextY {
+						// There is a conflicted one-way passage.
+						if rooms[origW][z][nextY][nextX].passageY == passageOneWayForward {
+							continue retry
+						}
+						if rooms[origW][z][nextY][nextX].passageY != passageWall {
+							allWall = false
+							if rooms[origW][z][nextY][nextX].passageY != passageOneWayBackward {
+								allWallOrOneWay = false
+							}
+						}
+					}
+				}
+				if allWall {
+					oneWay = b.random.IntN(5) == 0
+				} else if allWallOrOneWay {
+					oneWay = true
+				}
+				if allWallOrOneWay {
+					// A branch must have a one-way passage.
+					// Just before the end of the branch, the passage should be one-way so that branches are created more easily.
+					if isGoal(nextX, nextY, nextZ, nextW, rooms, count+1) {
+						oneWay = true
+						goalReached = true
+						found = true
+						break
+					}
+				}
+				fallthrough
+			default:
+				if rooms[nextW][nextZ][nextY][nextX].progress != 0 {
+					visited = true
+				}
+			}
+
+			if !visited {
+				found = true
+				break
+			}
+
+			if isGoal(nextX, nextY, nextZ, nextW, rooms, count+1) {
+				goalReached = true
+				found = true
+				break
+			}
+		}
+
+		// Give up when no new path is created.
+		if !found {
+			return nil
+		}
+
+		if oneWay {
+			oneWayExists = true
+		}
+
+		switch {
+		case x < nextX:
+			rooms[w][z][y][x].passageX = passagePassable
+		case x > nextX:
+			rooms[w][z][y][nextX].passageX = passagePassable
+		case y < nextY:
+			if oneWay {
+				for z := range b.depth0 {
+					if z == nextZ && w == nextW {
+						rooms[w][z][y][x].passageY = passageOneWayForward
+						continue
+					}
+					if rooms[w][z][y][x].passageY == passageOneWayBackward {
+						panic("not reached")
+					}
+					if rooms[w][z][y][x].passageY == passagePassable {
+						panic("not reached")
+					}
+				}
+			} else {
+				for z := range b.depth0 {
+					if z == nextZ && w == nextW {
+						rooms[w][z][y][x].passageY = passagePassable
+						continue
+					}
+					if rooms[w][z][y][x].passageY == passageOneWayForward {
+						panic("not reached")
+					}
+					if rooms[w][z][y][x].passageY == passageOneWayBackward {
+						panic("not reached")
+					}
+				}
+			}
+		case y > nextY:
+			if oneWay {
+				for z := range b.depth0 {
+					if z == nextZ && w == nextW {
+						rooms[w][z][nextY][x].passageY = passageOneWayBackward
+						continue
+					}
+					if rooms[w][z][nextY][x].passageY == passageOneWayForward {
+						panic("not reached")
+					}
+					if rooms[w][z][nextY][x].passageY == passagePassable {
+						panic("not reached")
+					}
+				}
+			} else {
+				for z := range b.depth0 {
+					if z == nextZ && w == nextW {
+						rooms[w][z][nextY][x].passageY = passagePassable
+						continue
+					}
+					if rooms[w][z][nextY][x].passageY == passageOneWayForward {
+						panic("not reached")
+					}
+					if rooms[w][z][nextY][x].passageY == passageOneWayBackward {
+						panic("not reached")
+					}
+				}
+			}
+		case z != nextZ:
+			// The last Z's passage is always wall
+			for z := range b.depth0 - 1 {
+				rooms[w][z][y][x].passageZ = passagePassable
+			}
+		case w != nextW:
+			// The last W's passage is always wall
+			for w := range b.depth1 - 1 {
+				rooms[w][z][y][x].passageW = passagePassable
+			}
+		}
+
+		if z != nextZ {
+			origZ := z
+			for z := range b.depth0 {
+				rooms[nextW][z][nextY][nextX].progress = count + abs(origZ-z)
+			}
+		} else if w != nextW {
+			origW := w
+			for w := range b.depth1 {
+				rooms[w][nextZ][nextY][nextX].progress = count + abs(origW-w)
+			}
+		} else {
+			rooms[nextW][nextZ][nextY][nextX].progress = count + 1
+		}
+		count++
+
+		if goalReached {
+			break
+		}
+
+		x, y, z, w = nextX, nextY, nextZ, nextW
+	}
+
+	if !oneWayExists {
+		return nil
+	}
+	return rooms
+}
+
+func (b *Building) requiredVisitedRoomCount() int {
+	return (b.width * b.height * b.depth0 * b.depth1) * 8 / 10
+}
+
+func (b *Building) visitedRoomCount(rooms [][][][]room) int {
+	var visited int
+	for w := range b.depth1 {
+		for z := range b.depth0 {
+			for y := range b.height {
+				for x := range b.width {
+					if rooms[w][z][y][x].progress > 0 {
+						visited++
+					}
+				}
+			}
+		}
+	}
+	return visited
+}
+
+func (b *Building) areEnoughRoomsVisited(rooms [][][][]room) bool {
+	var visited int
+	threshold := b.requiredVisitedRoomCount()
+	for w := range b.depth1 {
+		for z := range b.depth0 {
+			for y := range b.height {
+				for x := range b.width {
+					if rooms[w][z][y][x].progress > 0 {
+						visited++
+						if visited >= threshold {
+							return true
+						}
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// GridSize is the size of a tile in pixels.
+const GridSize = 16
+
+const (
+	roomYGridCount = 3
+)
+
+func (b *Building) roomXGridCount() int {
+	switch b.depth1 {
+	case 1:
+		return 6
+	case 2:
+		return 8
+	default:
+		panic("not reached")
+	}
+}
+
+func (b *Building) setTiles(rooms [][][][]room) {
+	roomXGridCount := b.roomXGridCount()
+
+	width := b.width*roomXGridCount + 1
+	height := b.height*roomYGridCount + 2
+
+	b.tiles = make([][]Tile, height)
+	for y := range b.tiles {
+		b.tiles[y] = make([]Tile, width)
+		for x := range b.tiles[y] {
+			b.tiles[y][x].Walls = make([]bool, b.depth1)
+			b.tiles[y][x].Ladders = make([]bool, b.depth1)
+			b.tiles[y][x].Switches = make([]bool, b.depth1)
+			b.tiles[y][x].WallColors = make([]int, b.depth1)
+			b.tiles[y][x].LadderColors = make([]int, b.depth1)
+		}
+	}
+
+	// Set the outside walls.
+	for x := range b.tiles[0] {
+		for i := range b.tiles[0][x].Walls {
+			b.tiles[0][x].Walls[i] = true
+		}
+	}
+	for y := range b.tiles {
+		for i := range b.tiles[y][0].Walls {
+			b.tiles[y][0].Walls[i] = true
+		}
+	}
+	for i := range b.tiles[height-1][width-1].Walls {
+		b.tiles[height-1][width-1].Walls[i] = true
+	}
+
+	// Set the goal.
+	b.tiles[height-1][width-roomXGridCount-1].Goal = true
+
+	for y := range b.height {
+		for x := range b.width {
+			b.setTilesForRoom(rooms, x, y)
+		}
+	}
+}
+
+func (b *Building) setTilesForRoom(rooms [][][][]room, roomX, roomY int) {
+	const (
+		edgeOffsetX = 1
+		edgeOffsetY = 1
+	)
+	roomXGridCount := b.roomXGridCount()
+
+	// Add walls.
+	colors, oks := b.wallColors(rooms, roomX, roomY)
+	for j := range roomYGridCount - 1 {
+		x := roomX*roomXGridCount + roomXGridCount - 1 + edgeOffsetX
+		x -= b.depth1 - 1
+		y := roomY*roomYGridCount + j + edgeOffsetY
+		allNonColorWall := true
+		for w := range b.depth1 {
+			if !oks[w] {
+				allNonColorWall = false
+				break
+			}
+			if colors[w] != 0 {
+				allNonColorWall = false
+				break
+			}
+		}
+		if allNonColorWall {
+			for w := range b.depth1 {
+				b.tiles[y][x+(b.depth1-1)].Walls[w] = true
+			}
+		} else {
+			for w := range b.depth1 {
+				if !oks[w] {
+					continue
+				}
+				b.tiles[y][x+w].Walls[w] = true
+				b.tiles[y][x+w].WallColors[w] = colors[w]
+			}
+		}
+	}
+
+	// Add a ceiling.
+	for i := range roomXGridCount {
+		x := roomX*roomXGridCount + i + edgeOffsetX
+		y := (roomY+1)*roomYGridCount - 1 + edgeOffsetY
+		for w := range b.depth1 {
+			b.tiles[y][x].Walls[w] = true
+		}
+	}
+
+	// Add ladders.
+	passageYs := make([]passage, b.depth1)
+	for i := range passageYs {
+		passageYs[i] = passageWall
+	}
+	for w := range b.depth1 {
+		for z := range b.depth0 {
+			room := rooms[w][z][roomY][roomX]
+			if room.passageY == passageWall {
+				continue
+			}
+			if passageYs[w] == passageWall {
+				passageYs[w] = room.passageY
+				continue
+			}
+			if passageYs[w] != room.passageY {
+				panic("not reached")
+			}
+			passageYs[w] = room.passageY
+		}
+	}
+	colors, oks = b.ladderColors(rooms, roomX, roomY)
+	for j := range roomYGridCount {
+		y := roomY*roomYGridCount + j + edgeOffsetY
+		for w := range b.depth1 {
+			if !oks[w] {
+				continue
+			}
+			x := roomX*roomXGridCount + 1 + ((roomY + w) % 2) + edgeOffsetX
+			b.tiles[y][x].Ladders[w] = true
+			b.tiles[y][x].LadderColors[w] = colors[w]
+			if passageYs[w] == passageOneWayForward {
+				b.tiles[y][x].Upward = true
+			}
+			if passageYs[w] == passageOneWayBackward {
+				b.tiles[y][x].Downward = true
+			}
+		}
+	}
+
+	// Add switches.
+	for w := range b.depth1 {
+		if rooms[w][0][roomY][roomX].passageZ != passageWall {
+			x := roomX*roomXGridCount + 3 + w + edgeOffsetX
+			y := roomY*roomYGridCount + edgeOffsetY
+			b.tiles[y][x].Switches[w] = true
+		}
+	}
+
+	// Add doors.
+	if color, ok := b.doorColor(rooms, roomX, roomY); ok {
+		x := roomX*roomXGridCount + 5 + edgeOffsetX
+		y := roomY*roomYGridCount + edgeOffsetY
+		b.tiles[y][x].Door = true
+		b.tiles[y][x].DoorColor = color
+		b.tiles[y+1][x].DoorUpper = true
+		b.tiles[y+1][x].DoorColor = color
+	}
+}
+
+func (b *Building) wallColors(rooms [][][][]room, roomX, roomY int) (colors []int, oks []bool) {
+	colors = make([]int, b.depth1)
+	oks = make([]bool, b.depth1)
+	for w := range b.depth1 {
+		x0 := rooms[w][0][roomY][roomX].passageX == passageWall
+		x1 := rooms[w][1][roomY][roomX].passageX == passageWall
+		if !x0 && x1 {
+			colors[w] = 1 // TODO: Add (w * b.depth0)?
+		}
+		if x0 && !x1 {
+			colors[w] = 2
+		}
+		if x0 || x1 {
+			oks[w] = true
+		}
+	}
+	return
+}
+
+func (b *Building) ladderColors(rooms [][][][]room, roomX, roomY int) (colors []int, oks []bool) {
+	colors = make([]int, b.depth1)
+	oks = make([]bool, b.depth1)
+	for w := range b.depth1 {
+		y0 := rooms[w][0][roomY][roomX].passageY != passageWall
+		y1 := rooms[w][1][roomY][roomX].passageY != passageWall
+		if y0 && !y1 {
+			colors[w] = 1
+		}
+		if !y0 && y1 {
+			colors[w] = 2
+		}
+		if y0 || y1 {
+			oks[w] = true
+		}
+	}
+	return
+}
+
+func (b *Building) doorColor(rooms [][][][]room, roomX, roomY int) (color int, ok bool) {
+	w0 := rooms[0][0][roomY][roomX].passageW != passageWall
+	w1 := rooms[0][1][roomY][roomX].passageW != passageWall
+	if w0 && !w1 {
+		color = 1
+	}
+	if !w0 && w1 {
+		color = 2
+	}
+	if w0 || w1 {
+		ok = true
+	}
+	return
+}
+
+func (b *Building) hasSwitch(x, y int, currentDepth1 int) bool {
+	return b.tiles[y][x].Switches[currentDepth1]
+}
+
+func (b *Building) hasDoor(x, y int, currentDepth0 int) bool {
+	if !b.tiles[y][x].Door {
+		return false
+	}
+	return b.tiles[y][x].DoorColor == 0 || b.tiles[y][x].DoorColor-1 == currentDepth0
+}
+
+func (b *Building) passable(nextX, nextY int, prevY int, currentDepth0 int, currentDepth1 int) bool {
+	if nextY < 0 || len(b.tiles) <= nextY || nextX < 0 || len(b.tiles[nextY]) <= nextX {
+		return false
+	}
+	if !b.canBeInTile(nextX, nextY, currentDepth0, currentDepth1) {
+		return false
+	}
+	if !b.canStandOnTile(nextX, nextY-1, currentDepth0, currentDepth1) {
+		return false
+	}
+	if nextY > prevY && !b.canGoUp(nextX, nextY, currentDepth0, currentDepth1) {
+		return false
+	}
+	if nextY < prevY && !b.canGoDown(nextX, nextY, currentDepth0, currentDepth1) {
+		return false
+	}
+	return true
+}
+
+func (b *Building) canBeInTile(x, y int, currentDepth0 int, currentDepth1 int) bool {
+	if y < 0 || len(b.tiles) <= y || x < 0 || len(b.tiles[y]) <= x {
+		return false
+	}
+	t := b.tiles[y][x]
+	// A ladder is passable, even though this is a wall.
+	if t.Ladders[currentDepth1] {
+		if t.LadderColors[currentDepth1] == 0 || t.LadderColors[currentDepth1]-1 == currentDepth0 {
+			return true
+		}
+	}
+	// A wall is not passable.
+	if t.Walls[currentDepth1] {
+		if t.WallColors[currentDepth1] == 0 || (t.WallColors[currentDepth1]-1 != currentDepth0) {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *Building) canStandOnTile(x, y int, currentDepth0 int, currentDepth1 int) bool {
+	if y < 0 || len(b.tiles) <= y || x < 0 || len(b.tiles[y]) <= x {
+		return false
+	}
+	t := b.tiles[y][x]
+	// A player can stand on a ladder.
+	if t.Ladders[currentDepth1] {
+		if t.LadderColors[currentDepth1] == 0 || t.LadderColors[currentDepth1]-1 == currentDepth0 {
+			return true
+		}
+	}
+	// A player can stand on a wall.
+	if t.Walls[currentDepth1] {
+		if t.WallColors[currentDepth1] == 0 || (t.WallColors[currentDepth1]-1 != currentDepth0) {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *Building) canGoUp(x, y int, currentDepth0 int, currentDepth1 int) bool {
+	if y < 0 || len(b.tiles) <= y || x < 0 || len(b.tiles[y]) <= x {
+		return false
+	}
+	t := b.tiles[y][x]
+	if !t.Ladders[currentDepth1] {
+		return true
+	}
+	if t.LadderColors[currentDepth1] > 0 && t.LadderColors[currentDepth1]-1 != currentDepth0 {
+		return true
+	}
+	return !t.Downward
+}
+
+func (b *Building) canGoDown(x, y int, currentDepth0 int, currentDepth1 int) bool {
+	if y < 0 || len(b.tiles) <= y || x < 0 || len(b.tiles[y]) <= x {
+		return false
+	}
+	t := b.tiles[y][x]
+	if !t.Ladders[currentDepth1] {
+		return true
+	}
+	if t.LadderColors[currentDepth1] > 0 && t.LadderColors[currentDepth1]-1 != currentDepth0 {
+		return true
+	}
+	return !t.Upward
+}
+
+// isOneWayLadder reports whether there is a usable one-way ladder at the tile.
+func (b *Building) isOneWayLadder(x, y int, currentDepth0 int, currentDepth1 int) bool {
+	if y < 0 || len(b.tiles) <= y || x < 0 || len(b.tiles[y]) <= x {
+		return false
+	}
+	t := b.tiles[y][x]
+	if !t.Ladders[currentDepth1] {
+		return false
+	}
+	if t.LadderColors[currentDepth1] > 0 && t.LadderColors[currentDepth1]-1 != currentDepth0 {
+		return false
+	}
+	return t.Upward || t.Downward
+}
+
+func (b *Building) isGoal(x, y int) bool {
+	return b.tiles[y][x].Goal
+}
+
+// FloorNumber returns the floor number of the tile row y. The first floor is 1.
+func (b *Building) FloorNumber(y int) int {
+	return (y-1)/roomYGridCount + 1
+}
+
+// FloorCount returns the number of the floors including the roof.
+func (b *Building) FloorCount() int {
+	return b.height + 1
+}
+
+// RoomAt returns the room that contains the tile (x, y).
+func (b *Building) RoomAt(x, y int) (roomX, roomY int, ok bool) {
+	roomX = (x - 1) / b.roomXGridCount()
+	roomY = (y - 1) / roomYGridCount
+	if x < 1 || y < 1 || roomX >= b.width || roomY >= b.height {
+		return 0, 0, false
+	}
+	return roomX, roomY, true
+}
+
+func (b *Building) Difficulty() Difficulty {
+	return b.difficulty
+}
+
+func (b *Building) Seed() uint64 {
+	return b.seed
+}
+
+// Tiles returns the tiles of the building. Tiles()[y][x] is the tile at (x, y), and the Y axis points upward.
+// The returned tiles must not be modified.
+func (b *Building) Tiles() [][]Tile {
+	return b.tiles
+}
+
+// RoomCount returns the number of the rooms in a row and a column.
+func (b *Building) RoomCount() (width, height int) {
+	return b.width, b.height
+}
+
+// RoomGridSize returns the size of a room in tiles.
+func (b *Building) RoomGridSize() (width, height int) {
+	return b.roomXGridCount(), roomYGridCount
+}
+
+// Depths returns the number of the states of the switches and the doors.
+func (b *Building) Depths() (depth0, depth1 int) {
+	return b.depth0, b.depth1
+}
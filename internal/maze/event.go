@@ -0,0 +1,39 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package maze
+
+// Event is an event that happens in a run.
+type Event int
+
+const (
+	// EventStep happens when the player starts moving to the next tile.
+	EventStep Event = iota
+
+	// EventLadder happens when the player starts climbing up or down a ladder.
+	EventLadder
+
+	// EventOneWayLadder happens with EventLadder when the ladder is one-way.
+	EventOneWayLadder
+
+	// EventSwitch happens when the switches are toggled.
+	EventSwitch
+
+	// EventDoor happens when the player passes through a door.
+	EventDoor
+
+	// EventBlocked happens when the player tries to move but cannot.
+	EventBlocked
+
+	// EventGoal happens when the player reaches the goal.
+	EventGoal
+)
+
+// Events returns the events that happened in the last Update and after that.
+func (r *Run) Events() []Event {
+	return r.events
+}
+
+func (r *Run) emit(e Event) {
+	r.events = append(r.events, e)
+}
@@ -0,0 +1,139 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package maze
+
+// InputState is the player's input for one tick.
+type InputState uint8
+
+const (
+	InputUp InputState = 1 << iota
+	InputDown
+	InputLeft
+	InputRight
+
+	// InputInteract means that the interaction key is just pressed at the tick.
+	InputInteract
+)
+
+const directionMask = InputUp | InputDown | InputLeft | InputRight
+
+// Input is a source of the player's input, read once per tick.
+//
+// game.Controls is the keyboard implementation.
+type Input interface {
+	// Direction returns the directions held at the current tick, as a combination of InputUp, InputDown, InputLeft and InputRight.
+	Direction() InputState
+
+	// IsInteractJustPressed reports whether the interaction is just pressed at the current tick.
+	IsInteractJustPressed() bool
+}
+
+// SequentialInput is an input that changes by ticks, such as a replay.
+// Run.Update calls Advance after reading the input.
+type SequentialInput interface {
+	Input
+	Advance()
+}
+
+func readInput(in Input) InputState {
+	s := in.Direction() & directionMask
+	if in.IsInteractJustPressed() {
+		s |= InputInteract
+	}
+	if in, ok := in.(SequentialInput); ok {
+		in.Advance()
+	}
+	return s
+}
+
+// Inputs combines multiple inputs, e.g., a keyboard and gamepads.
+type Inputs []Input
+
+func (i Inputs) Direction() InputState {
+	var in InputState
+	for _, input := range i {
+		in |= input.Direction()
+	}
+	return in
+}
+
+func (i Inputs) IsInteractJustPressed() bool {
+	var pressed bool
+	// Query all the inputs so that sequential inputs are read consistently.
+	for _, input := range i {
+		if input.IsInteractJustPressed() {
+			pressed = true
+		}
+	}
+	return pressed
+}
+
+func (i Inputs) Advance() {
+	for _, input := range i {
+		if input, ok := input.(SequentialInput); ok {
+			input.Advance()
+		}
+	}
+}
+
+// ReplayInput plays back the input states recorded by Run.Replay.
+// ReplayInput inputs nothing after the end of the replay.
+type ReplayInput struct {
+	replay []InputState
+	pos    int
+}
+
+func NewReplayInput(replay []InputState) *ReplayInput {
+	return &ReplayInput{replay: replay}
+}
+
+func (r *ReplayInput) current() InputState {
+	if r.pos >= len(r.replay) {
+		return 0
+	}
+	return r.replay[r.pos]
+}
+
+func (r *ReplayInput) Direction() InputState {
+	return r.current() & directionMask
+}
+
+func (r *ReplayInput) IsInteractJustPressed() bool {
+	return r.current()&InputInteract != 0
+}
+
+func (r *ReplayInput) Advance() {
+	r.pos++
+}
+
+// IsOver reports whether all the input states are played.
+func (r *ReplayInput) IsOver() bool {
+	return r.pos >= len(r.replay)
+}
+
+// ScriptedInput is an input from a queue of input states, e.g., for a bot.
+type ScriptedInput struct {
+	ReplayInput
+}
+
+// Hold queues the input state for the ticks.
+func (s *ScriptedInput) Hold(in InputState, ticks int) {
+	if s.IsOver() {
+		s.Reset()
+	}
+	for range ticks {
+		s.replay = append(s.replay, in)
+	}
+}
+
+// Interact queues a tick to press the interaction.
+func (s *ScriptedInput) Interact() {
+	s.Hold(InputInteract, 1)
+}
+
+// Reset removes all the queued input states.
+func (s *ScriptedInput) Reset() {
+	s.replay = s.replay[:0]
+	s.pos = 0
+}
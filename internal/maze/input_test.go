@@ -0,0 +1,162 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package maze
+
+import (
+	"testing"
+)
+
+// staticInput is an input that never changes, like a key held down.
+type staticInput struct {
+	direction InputState
+	interact  bool
+}
+
+func (s staticInput) Direction() InputState {
+	return s.direction
+}
+
+func (s staticInput) IsInteractJustPressed() bool {
+	return s.interact
+}
+
+func readAll(in Input, ticks int) []InputState {
+	var states []InputState
+	for range ticks {
+		states = append(states, readInput(in))
+	}
+	return states
+}
+
+func TestReadInput(t *testing.T) {
+	testCases := []struct {
+		name string
+		in   Input
+		want InputState
+	}{
+		{
+			name: "none",
+			in:   staticInput{},
+			want: 0,
+		},
+		{
+			name: "direction",
+			in:   staticInput{direction: InputUp | InputLeft},
+			want: InputUp | InputLeft,
+		},
+		{
+			name: "interact",
+			in:   staticInput{direction: InputDown, interact: true},
+			want: InputDown | InputInteract,
+		},
+		{
+			// Direction must not report the interaction.
+			name: "interact in direction",
+			in:   staticInput{direction: InputRight | InputInteract},
+			want: InputRight,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := readInput(tc.in); got != tc.want {
+				t.Errorf("readInput(): got: %v, want: %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestReplayInput(t *testing.T) {
+	replay := []InputState{InputUp, InputUp | InputInteract, 0, InputLeft}
+	in := NewReplayInput(replay)
+	got := readAll(in, len(replay)+2)
+	want := append(replay, 0, 0)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("tick %d: got: %v, want: %v", i, got[i], want[i])
+		}
+	}
+	if !in.IsOver() {
+		t.Errorf("IsOver(): got: false, want: true")
+	}
+}
+
+func TestInputs(t *testing.T) {
+	a := NewReplayInput([]InputState{InputUp, 0, InputInteract})
+	b := NewReplayInput([]InputState{InputLeft, InputInteract, 0})
+	in := Inputs{a, staticInput{direction: InputRight}, b}
+
+	got := readAll(in, 4)
+	want := []InputState{
+		InputUp | InputLeft | InputRight,
+		InputRight | InputInteract,
+		InputRight | InputInteract,
+		InputRight,
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("tick %d: got: %v, want: %v", i, got[i], want[i])
+		}
+	}
+
+	// Each sequential input advances once per tick.
+	if !a.IsOver() || !b.IsOver() {
+		t.Errorf("IsOver(): got: %t, %t, want: true, true", a.IsOver(), b.IsOver())
+	}
+}
+
+func TestScriptedInputHold(t *testing.T) {
+	var in ScriptedInput
+	in.Hold(InputUp, 2)
+	in.Interact()
+	in.Hold(InputRight, 1)
+
+	got := readAll(&in, 5)
+	want := []InputState{InputUp, InputUp, InputInteract, InputRight, 0}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("tick %d: got: %v, want: %v", i, got[i], want[i])
+		}
+	}
+
+	// Hold after all the states are played starts a new script instead of appending to the played one.
+	in.Hold(InputDown, 1)
+	got = readAll(&in, 2)
+	want = []InputState{InputDown, 0}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("tick %d after the reset: got: %v, want: %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestScriptedInputHoldWhilePlaying(t *testing.T) {
+	var in ScriptedInput
+	in.Hold(InputUp, 2)
+	if got := readInput(&in); got != InputUp {
+		t.Errorf("tick 0: got: %v, want: %v", got, InputUp)
+	}
+
+	// Hold while playing appends to the queue.
+	in.Hold(InputLeft, 1)
+	got := readAll(&in, 3)
+	want := []InputState{InputUp, InputLeft, 0}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("tick %d: got: %v, want: %v", i+1, got[i], want[i])
+		}
+	}
+}
+
+func TestScriptedInputReset(t *testing.T) {
+	var in ScriptedInput
+	in.Hold(InputUp, 3)
+	readInput(&in)
+	in.Reset()
+	if !in.IsOver() {
+		t.Errorf("IsOver() after Reset: got: false, want: true")
+	}
+	if got := readInput(&in); got != 0 {
+		t.Errorf("readInput() after Reset: got: %v, want: 0", got)
+	}
+}
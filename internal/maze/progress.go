@@ -0,0 +1,26 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package maze
+
+// Progress is the progress of generating a building.
+type Progress struct {
+	// Attempts is the number of attempts to generate the rooms. A failed attempt starts over.
+	Attempts int
+
+	// VisitedRooms is the number of the rooms on the path and the branches in the current attempt.
+	// The generation finishes when VisitedRooms reaches RequiredRooms.
+	VisitedRooms  int
+	RequiredRooms int
+
+	// Branches is the number of the branches added in the current attempt.
+	Branches int
+}
+
+// Rate returns the progress from 0 to 1.
+func (p Progress) Rate() float64 {
+	if p.RequiredRooms == 0 {
+		return 0
+	}
+	return min(float64(p.VisitedRooms)/float64(p.RequiredRooms), 1)
+}
@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package maze
+
+import (
+	"context"
+	"fmt"
+)
+
+// MaxReplayTicks is the maximum length of a replay to simulate.
+const MaxReplayTicks = 60 * 60 * 60
+
+// Replay returns the inputs of the current run for each tick.
+func (r *Run) Replay() []InputState {
+	return append([]InputState{}, r.replay...)
+}
+
+// Simulate replays the inputs in the building without rendering, and returns the statistics of the run.
+// Simulate returns an error if the goal is not reached exactly at the end of the replay.
+func Simulate(difficulty Difficulty, seed uint64, speed Speed, replay []InputState) (Stats, error) {
+	if difficulty < LevelTutorial || difficulty > LevelSugoi {
+		return Stats{}, fmt.Errorf("maze: invalid difficulty: %d", difficulty)
+	}
+	if speed < SpeedSlow || speed > SpeedFast {
+		return Stats{}, fmt.Errorf("maze: invalid speed: %d", speed)
+	}
+	if len(replay) > MaxReplayTicks {
+		return Stats{}, fmt.Errorf("maze: the replay is too long: %d ticks", len(replay))
+	}
+
+	b, err := NewBuilding(context.Background(), difficulty, seed, nil)
+	if err != nil {
+		return Stats{}, err
+	}
+	r := NewRun(b)
+	r.speed = speed
+	for i, in := range replay {
+		if r.goalReached {
+			return Stats{}, fmt.Errorf("maze: the goal was reached at tick %d before the end of the replay", i)
+		}
+		r.Step(in)
+	}
+	if !r.goalReached {
+		return Stats{}, fmt.Errorf("maze: the goal was not reached")
+	}
+	return r.Stats(), nil
+}
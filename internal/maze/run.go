@@ -0,0 +1,240 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package maze
+
+// Run is the state of a player in a building.
+// Multiple runs can share the same Building.
+type Run struct {
+	building      *Building
+	playerX       int
+	playerY       int
+	dx            int
+	dy            int
+	currentDepth0 int
+	currentDepth1 int
+	goalReached   bool
+	visitedRooms  [][]bool
+	trail         Trail
+	stats         Stats
+	replay        []InputState
+	lastInput     InputState
+	events        []Event
+	speed         Speed
+}
+
+// NewRun creates a run starting at the entrance of the building.
+func NewRun(building *Building) *Run {
+	r := &Run{
+		building: building,
+		playerX:  1,
+		playerY:  1,
+	}
+
+	r.visitedRooms = make([][]bool, building.height)
+	for y := range r.visitedRooms {
+		r.visitedRooms[y] = make([]bool, building.width)
+	}
+	r.trail = newTrail(len(building.tiles[0]), len(building.tiles))
+	r.visit()
+
+	return r
+}
+
+func (r *Run) visit() {
+	if x, y, ok := r.building.RoomAt(r.playerX, r.playerY); ok {
+		r.visitedRooms[y][x] = true
+	}
+	r.trail.add(r.playerX, r.playerY, r.building.depth0, r.currentDepth0, r.currentDepth1)
+}
+
+func (r *Run) Building() *Building {
+	return r.building
+}
+
+func (r *Run) Difficulty() Difficulty {
+	return r.building.difficulty
+}
+
+func (r *Run) Seed() uint64 {
+	return r.building.seed
+}
+
+// Speed returns the player's movement speed.
+func (r *Run) Speed() Speed {
+	return r.speed
+}
+
+// SetSpeed sets the player's movement speed.
+func (r *Run) SetSpeed(speed Speed) {
+	r.speed = speed
+}
+
+func (r *Run) IsGoalReached() bool {
+	return r.goalReached
+}
+
+// ToggleSwitches changes the state of the switches regardless of the player's position.
+func (r *Run) ToggleSwitches() {
+	r.emit(EventSwitch)
+	r.stats.SwitchPresses++
+	r.currentDepth0++
+	r.currentDepth0 %= r.building.depth0
+	r.visit()
+}
+
+// ToggleDoors changes the state of the doors regardless of the player's position.
+func (r *Run) ToggleDoors() {
+	r.emit(EventDoor)
+	r.stats.SwitchPresses++
+	r.currentDepth1++
+	r.currentDepth1 %= r.building.depth1
+	r.visit()
+}
+
+// Update advances the run by one tick with the input.
+func (r *Run) Update(in Input) {
+	if r.goalReached {
+		r.events = r.events[:0]
+		return
+	}
+	r.Step(readInput(in))
+}
+
+// Step advances the run by one tick with the input state.
+// Step doesn't depend on any input devices, so it works in a headless simulation.
+func (r *Run) Step(in InputState) {
+	r.events = r.events[:0]
+	if r.goalReached {
+		return
+	}
+	r.replay = append(r.replay, in)
+	r.update(in)
+}
+
+func (r *Run) update(in InputState) {
+	defer func() {
+		r.lastInput = in
+	}()
+
+	b := r.building
+	v := r.speed.pixelsPerTick()
+
+	if r.dx != 0 || r.dy != 0 {
+		if r.dx > 0 {
+			r.dx += v
+		} else if r.dx < 0 {
+			r.dx -= v
+		}
+		if r.dy > 0 {
+			r.dy += v
+		} else if r.dy < 0 {
+			r.dy -= v
+		}
+		if r.dx >= GridSize {
+			r.playerX++
+			r.dx = 0
+		}
+		if r.dx <= -GridSize {
+			r.playerX--
+			r.dx = 0
+		}
+		if r.dy >= GridSize {
+			r.playerY++
+			r.dy = 0
+		}
+		if r.dy <= -GridSize {
+			r.playerY--
+			r.dy = 0
+		}
+		if r.dx == 0 && r.dy == 0 {
+			r.stats.Steps++
+			r.visit()
+		}
+		if b.isGoal(r.playerX, r.playerY) {
+			r.goalReached = true
+			r.emit(EventGoal)
+		}
+		return
+	}
+
+	prevX, prevY := r.playerX, r.playerY
+	if in&InputInteract != 0 {
+		if b.hasSwitch(prevX, prevY, r.currentDepth1) {
+			r.ToggleSwitches()
+		}
+		if b.hasDoor(prevX, prevY, r.currentDepth0) {
+			r.ToggleDoors()
+		}
+	}
+
+	nextX, nextY := prevX, prevY
+	if in&InputUp != 0 {
+		nextY++
+	} else if in&InputDown != 0 {
+		nextY--
+	} else if in&InputLeft != 0 {
+		nextX--
+	} else if in&InputRight != 0 {
+		nextX++
+	}
+	if !b.passable(nextX, nextY, prevY, r.currentDepth0, r.currentDepth1) {
+		if in != r.lastInput {
+			r.emit(EventBlocked)
+		}
+		return
+	}
+	if nextX != prevX || nextY != prevY {
+		r.emit(EventStep)
+	}
+	if nextY != prevY {
+		r.emit(EventLadder)
+		if b.isOneWayLadder(prevX, prevY, r.currentDepth0, r.currentDepth1) || b.isOneWayLadder(nextX, nextY, r.currentDepth0, r.currentDepth1) {
+			r.emit(EventOneWayLadder)
+			r.stats.OneWayLadders++
+		}
+	}
+	if nextX > r.playerX {
+		r.dx = v
+	}
+	if nextX < r.playerX {
+		r.dx = -v
+	}
+	if nextY > r.playerY {
+		r.dy = v
+	}
+	if nextY < r.playerY {
+		r.dy = -v
+	}
+}
+
+// PlayerPosition returns the player's position in pixels.
+// The Y axis points upward.
+func (r *Run) PlayerPosition() (x, y int) {
+	return r.playerX*GridSize + r.dx, r.playerY*GridSize + r.dy
+}
+
+// PlayerTile returns the tile where the player is, or where the player is leaving while moving.
+func (r *Run) PlayerTile() (x, y int) {
+	return r.playerX, r.playerY
+}
+
+// Floor returns the floor where the player is and the number of the floors.
+func (r *Run) Floor() (floor, floorCount int) {
+	return r.building.FloorNumber(r.playerY), r.building.FloorCount()
+}
+
+// DepthState returns the current state of the switches and the doors.
+func (r *Run) DepthState() (depth0, depth1 int) {
+	return r.currentDepth0, r.currentDepth1
+}
+
+// VisitedRooms returns whether each room has been visited. VisitedRooms()[y][x] is for the room at (x, y).
+func (r *Run) VisitedRooms() [][]bool {
+	return r.visitedRooms
+}
+
+// Trail returns the tiles the player has stood on.
+func (r *Run) Trail() Trail {
+	return r.trail
+}
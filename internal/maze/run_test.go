@@ -0,0 +1,215 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package maze
+
+import (
+	"context"
+	"testing"
+)
+
+func newTestRun(t *testing.T, difficulty Difficulty, seed uint64) *Run {
+	t.Helper()
+	b, err := NewBuilding(context.Background(), difficulty, seed, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewRun(b)
+}
+
+// playToGoal plays the run with the input until the goal is reached.
+func playToGoal(t *testing.T, r *Run, in Input) {
+	t.Helper()
+	for range MaxReplayTicks {
+		if r.IsGoalReached() {
+			return
+		}
+		r.Update(in)
+	}
+	t.Fatalf("the goal was not reached in %d ticks", MaxReplayTicks)
+}
+
+// solvedReplay returns the inputs of a run that reaches the goal with the solver's plan.
+func solvedReplay(t *testing.T, difficulty Difficulty, seed uint64, speed Speed) ([]InputState, Stats) {
+	t.Helper()
+	r := newTestRun(t, difficulty, seed)
+	r.SetSpeed(speed)
+	plan, ok := r.Solve()
+	if !ok {
+		t.Fatalf("Solve() failed: difficulty: %v, seed: %d", difficulty, seed)
+	}
+	playToGoal(t, r, plan.Input(speed))
+	return r.Replay(), r.Stats()
+}
+
+func TestRunStart(t *testing.T) {
+	r := newTestRun(t, LevelEasy, 1)
+	if x, y := r.PlayerTile(); x != 1 || y != 1 {
+		t.Errorf("PlayerTile(): got: (%d, %d), want: (1, 1)", x, y)
+	}
+	if floor, _ := r.Floor(); floor != 1 {
+		t.Errorf("Floor(): got: %d, want: 1", floor)
+	}
+	if got := r.Stats(); got != (Stats{}) {
+		t.Errorf("Stats(): got: %+v, want: zero", got)
+	}
+}
+
+func TestRunStepAndUpdate(t *testing.T) {
+	// Step with the input states and Update with the same inputs must behave the same.
+	replay, _ := solvedReplay(t, LevelEasy, 1, SpeedNormal)
+
+	stepped := newTestRun(t, LevelEasy, 1)
+	updated := newTestRun(t, LevelEasy, 1)
+	in := NewReplayInput(replay)
+	for i, s := range replay {
+		stepped.Step(s)
+		updated.Update(in)
+		if got, want := updated.Stats(), stepped.Stats(); got != want {
+			t.Fatalf("tick %d: Stats(): Update: %+v, Step: %+v", i, got, want)
+		}
+		x0, y0 := stepped.PlayerPosition()
+		x1, y1 := updated.PlayerPosition()
+		if x0 != x1 || y0 != y1 {
+			t.Fatalf("tick %d: PlayerPosition(): Update: (%d, %d), Step: (%d, %d)", i, x1, y1, x0, y0)
+		}
+	}
+	if !stepped.IsGoalReached() || !updated.IsGoalReached() {
+		t.Errorf("IsGoalReached(): Step: %t, Update: %t, want: true", stepped.IsGoalReached(), updated.IsGoalReached())
+	}
+}
+
+func TestRunScriptedInput(t *testing.T) {
+	r := newTestRun(t, LevelEasy, 1)
+	plan, ok := r.Solve()
+	if !ok {
+		t.Fatal("Solve() failed")
+	}
+	first := plan[0]
+	if first == ActionInteract {
+		t.Skip("the first action is not a move")
+	}
+
+	// Holding a direction for TicksPerMove ticks moves the player by one tile.
+	var in ScriptedInput
+	in.Hold(first.inputState(), SpeedNormal.TicksPerMove())
+	for !in.IsOver() {
+		r.Update(&in)
+	}
+	x, y := r.PlayerTile()
+	n, _ := r.building.next(solverState{x: 1, y: 1}, first)
+	if x != n.x || y != n.y {
+		t.Errorf("PlayerTile(): got: (%d, %d), want: (%d, %d)", x, y, n.x, n.y)
+	}
+	if px, py := r.PlayerPosition(); px != x*GridSize || py != y*GridSize {
+		t.Errorf("PlayerPosition(): got: (%d, %d), want: (%d, %d)", px, py, x*GridSize, y*GridSize)
+	}
+	if got := r.Stats().Steps; got != 1 {
+		t.Errorf("Stats().Steps: got: %d, want: 1", got)
+	}
+}
+
+func TestRunReplay(t *testing.T) {
+	for _, speed := range []Speed{SpeedSlow, SpeedNormal, SpeedFast} {
+		replay, stats := solvedReplay(t, LevelNormal, 2, speed)
+		if stats.Ticks != len(replay) {
+			t.Errorf("speed: %v: Stats().Ticks: got: %d, want: %d", speed, stats.Ticks, len(replay))
+		}
+
+		r := newTestRun(t, LevelNormal, 2)
+		r.SetSpeed(speed)
+		playToGoal(t, r, NewReplayInput(replay))
+		if got := r.Stats(); got != stats {
+			t.Errorf("speed: %v: Stats() of the replay: got: %+v, want: %+v", speed, got, stats)
+		}
+	}
+}
+
+func TestRunNoStepAfterGoal(t *testing.T) {
+	replay, stats := solvedReplay(t, LevelTutorial, 1, SpeedNormal)
+	r := newTestRun(t, LevelTutorial, 1)
+	for _, s := range replay {
+		r.Step(s)
+	}
+	r.Step(InputLeft)
+	r.Update(NewReplayInput([]InputState{InputLeft}))
+	if got := r.Stats(); got != stats {
+		t.Errorf("Stats() after the goal: got: %+v, want: %+v", got, stats)
+	}
+	if got := len(r.Replay()); got != len(replay) {
+		t.Errorf("len(Replay()) after the goal: got: %d, want: %d", got, len(replay))
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	for _, d := range []Difficulty{LevelTutorial, LevelEasy, LevelNormal} {
+		for _, speed := range []Speed{SpeedSlow, SpeedNormal, SpeedFast} {
+			replay, stats := solvedReplay(t, d, 3, speed)
+			got, err := Simulate(d, 3, speed, replay)
+			if err != nil {
+				t.Errorf("difficulty: %v, speed: %v: Simulate() failed: %v", d, speed, err)
+				continue
+			}
+			if got != stats {
+				t.Errorf("difficulty: %v, speed: %v: Simulate(): got: %+v, want: %+v", d, speed, got, stats)
+			}
+		}
+	}
+}
+
+func TestSimulateError(t *testing.T) {
+	replay, _ := solvedReplay(t, LevelEasy, 4, SpeedNormal)
+	testCases := []struct {
+		name       string
+		difficulty Difficulty
+		seed       uint64
+		speed      Speed
+		replay     []InputState
+	}{
+		{
+			name:       "invalid difficulty",
+			difficulty: LevelSugoi + 1,
+			seed:       4,
+			replay:     replay,
+		},
+		{
+			name:       "invalid speed",
+			difficulty: LevelEasy,
+			seed:       4,
+			speed:      SpeedFast + 1,
+			replay:     replay,
+		},
+		{
+			name:       "too long",
+			difficulty: LevelEasy,
+			seed:       4,
+			replay:     make([]InputState, MaxReplayTicks+1),
+		},
+		{
+			name:       "goal not reached",
+			difficulty: LevelEasy,
+			seed:       4,
+			replay:     replay[:len(replay)-1],
+		},
+		{
+			name:       "ticks after the goal",
+			difficulty: LevelEasy,
+			seed:       4,
+			replay:     append(replay, 0),
+		},
+		{
+			name:       "another speed",
+			difficulty: LevelEasy,
+			seed:       4,
+			speed:      SpeedSlow,
+			replay:     replay,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Simulate(tc.difficulty, tc.seed, tc.speed, tc.replay); err == nil {
+				t.Errorf("Simulate() must fail")
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package maze
+
+// Action is a step of a plan to reach the goal.
+type Action int
+
+const (
+	ActionUp Action = iota
+	ActionDown
+	ActionLeft
+	ActionRight
+	ActionInteract
+)
+
+func (a Action) inputState() InputState {
+	switch a {
+	case ActionUp:
+		return InputUp
+	case ActionDown:
+		return InputDown
+	case ActionLeft:
+		return InputLeft
+	case ActionRight:
+		return InputRight
+	case ActionInteract:
+		return InputInteract
+	default:
+		panic("not reached")
+	}
+}
+
+// Plan is a sequence of actions to reach the goal.
+type Plan []Action
+
+// Input converts the plan into per-tick inputs for a player moving at the speed.
+func (p Plan) Input(speed Speed) *ScriptedInput {
+	in := &ScriptedInput{}
+	for _, a := range p {
+		if a == ActionInteract {
+			in.Interact()
+			continue
+		}
+		// The direction is held until the player arrives at the next tile.
+		in.Hold(a.inputState(), speed.TicksPerMove())
+	}
+	return in
+}
+
+type solverState struct {
+	x      int
+	y      int
+	depth0 int
+	depth1 int
+}
+
+// next returns the state after the action, or false if the action does nothing.
+// next must be consistent with Run.update.
+func (b *Building) next(s solverState, a Action) (solverState, bool) {
+	if a == ActionInteract {
+		n := s
+		var toggled bool
+		if b.hasSwitch(n.x, n.y, n.depth1) {
+			n.depth0 = (n.depth0 + 1) % b.depth0
+			toggled = true
+		}
+		if b.hasDoor(n.x, n.y, n.depth0) {
+			n.depth1 = (n.depth1 + 1) % b.depth1
+			toggled = true
+		}
+		return n, toggled
+	}
+
+	n := s
+	switch a {
+	case ActionUp:
+		n.y++
+	case ActionDown:
+		n.y--
+	case ActionLeft:
+		n.x--
+	case ActionRight:
+		n.x++
+	}
+	if !b.passable(n.x, n.y, s.y, s.depth0, s.depth1) {
+		return s, false
+	}
+	return n, true
+}
+
+// Solve finds a plan with the fewest actions from the player's current state to the goal,
+// by a breadth-first search over the positions and the states of the switches and the doors.
+// Solve returns false if the goal is unreachable or the player is moving between tiles.
+func (r *Run) Solve() (Plan, bool) {
+	if r.goalReached || r.dx != 0 || r.dy != 0 {
+		return nil, false
+	}
+
+	type edge struct {
+		prev   solverState
+		action Action
+	}
+
+	start := solverState{
+		x:      r.playerX,
+		y:      r.playerY,
+		depth0: r.currentDepth0,
+		depth1: r.currentDepth1,
+	}
+	edges := map[solverState]edge{}
+	queue := []solverState{start}
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		for a := ActionUp; a <= ActionInteract; a++ {
+			n, ok := r.building.next(s, a)
+			if !ok || n == start {
+				continue
+			}
+			if _, ok := edges[n]; ok {
+				continue
+			}
+			edges[n] = edge{prev: s, action: a}
+			// The goal is checked only when the player arrives at a tile.
+			if a == ActionInteract || !r.building.isGoal(n.x, n.y) {
+				queue = append(queue, n)
+				continue
+			}
+
+			var plan Plan
+			for n != start {
+				e := edges[n]
+				plan = append(plan, e.action)
+				n = e.prev
+			}
+			for i, j := 0, len(plan)-1; i < j; i, j = i+1, j-1 {
+				plan[i], plan[j] = plan[j], plan[i]
+			}
+			return plan, true
+		}
+	}
+	return nil, false
+}
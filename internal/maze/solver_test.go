@@ -0,0 +1,45 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package maze
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSolve(t *testing.T) {
+	for _, d := range []Difficulty{LevelTutorial, LevelEasy} {
+		for seed := range uint64(8) {
+			for _, speed := range []Speed{SpeedSlow, SpeedNormal, SpeedFast} {
+				t.Run(fmt.Sprintf("%v/%d/%v", d, seed, speed), func(t *testing.T) {
+					r := newTestRun(t, d, seed)
+					r.SetSpeed(speed)
+					plan, ok := r.Solve()
+					if !ok {
+						t.Fatal("Solve() failed")
+					}
+					in := plan.Input(speed)
+					for !in.IsOver() {
+						r.Update(in)
+					}
+					if !r.IsGoalReached() {
+						x, y := r.PlayerTile()
+						t.Errorf("IsGoalReached(): got: false, want: true (player: (%d, %d))", x, y)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestSolveAfterGoal(t *testing.T) {
+	replay, _ := solvedReplay(t, LevelTutorial, 1, SpeedNormal)
+	r := newTestRun(t, LevelTutorial, 1)
+	for _, s := range replay {
+		r.Step(s)
+	}
+	if _, ok := r.Solve(); ok {
+		t.Errorf("Solve() after the goal must fail")
+	}
+}
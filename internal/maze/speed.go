@@ -0,0 +1,37 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package maze
+
+// Speed is the player's movement speed.
+type Speed int
+
+const (
+	SpeedSlow   Speed = -1
+	SpeedNormal Speed = 0
+	SpeedFast   Speed = 1
+)
+
+func (s Speed) String() string {
+	switch s {
+	case SpeedSlow:
+		return "Slow"
+	case SpeedNormal:
+		return "Normal"
+	case SpeedFast:
+		return "Fast"
+	default:
+		panic("not reached")
+	}
+}
+
+// pixelsPerTick returns how many pixels the player moves in one tick.
+func (s Speed) pixelsPerTick() int {
+	return 3 + int(s)
+}
+
+// TicksPerMove returns how many ticks the player takes to move to the next tile.
+func (s Speed) TicksPerMove() int {
+	v := s.pixelsPerTick()
+	return (GridSize + v - 1) / v
+}
@@ -0,0 +1,26 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package maze
+
+// Stats is the statistics of a run.
+type Stats struct {
+	// Ticks is the duration of the run.
+	Ticks int
+
+	// Steps is the number of tiles the player moved.
+	Steps int
+
+	// SwitchPresses is the number of times the switches and the doors were toggled.
+	SwitchPresses int
+
+	// OneWayLadders is the number of times the player used one-way ladders.
+	OneWayLadders int
+}
+
+// Stats returns the statistics of the current run.
+func (r *Run) Stats() Stats {
+	s := r.stats
+	s.Ticks = len(r.replay)
+	return s
+}
@@ -0,0 +1,25 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package maze
+
+// Trail records the tiles the player has stood on.
+// Each element is a bit set of the depth states (currentDepth0 and currentDepth1) at the time. See TrailBit.
+type Trail [][]uint8
+
+func newTrail(width, height int) Trail {
+	t := make(Trail, height)
+	for y := range t {
+		t[y] = make([]uint8, width)
+	}
+	return t
+}
+
+// TrailBit returns the bit for the depth state in an element of Trail.
+func TrailBit(depth0 int, currentDepth0, currentDepth1 int) uint8 {
+	return 1 << (currentDepth1*depth0 + currentDepth0)
+}
+
+func (t Trail) add(x, y int, depth0 int, currentDepth0, currentDepth1 int) {
+	t[y][x] |= TrailBit(depth0, currentDepth0, currentDepth1)
+}
@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package netrace
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"sync"
+)
+
+// Client is a connection to a race server.
+type Client struct {
+	conn     net.Conn
+	encoder  *json.Encoder
+	messages chan *Message
+
+	m   sync.Mutex
+	err error
+}
+
+// Dial connects to the race server at addr and joins the lobby.
+func Dial(addr string, name string) (*Client, error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{
+		conn:     conn,
+		encoder:  json.NewEncoder(conn),
+		messages: make(chan *Message, 64),
+	}
+	if err := c.send(&Message{Type: TypeJoin, Name: name}); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	go c.receive()
+	return c, nil
+}
+
+func (c *Client) receive() {
+	defer close(c.messages)
+	scanner := bufio.NewScanner(c.conn)
+	for scanner.Scan() {
+		var msg Message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			c.setErr(err)
+			return
+		}
+		c.messages <- &msg
+	}
+	c.setErr(scanner.Err())
+}
+
+func (c *Client) setErr(err error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.err == nil {
+		c.err = err
+	}
+}
+
+// Err returns the error that stopped receiving messages, if any.
+func (c *Client) Err() error {
+	c.m.Lock()
+	defer c.m.Unlock()
+	return c.err
+}
+
+// Messages returns the channel of the messages from the server.
+// The channel is closed when the connection is closed.
+func (c *Client) Messages() <-chan *Message {
+	return c.messages
+}
+
+func (c *Client) send(msg *Message) error {
+	return c.encoder.Encode(msg)
+}
+
+// SendState sends the player's state to the other players.
+func (c *Client) SendState(state PlayerState) error {
+	return c.send(&Message{Type: TypeState, State: &state})
+}
+
+// SendGoal tells the server that the player reached the goal.
+func (c *Client) SendGoal() error {
+	return c.send(&Message{Type: TypeGoal})
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
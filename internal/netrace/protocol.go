@@ -0,0 +1,55 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+// Package netrace implements a race over TCP among players on a LAN.
+//
+// A server and clients exchange newline-delimited JSON messages.
+// A client joins the lobby with a "join" message.
+// When enough players join, the server sends a "start" message with the building's difficulty and seed.
+// During the race, clients send "state" messages and the server relays them to the other players.
+// A client sends a "goal" message when reaching the goal, and the server announces the winner with a "winner" message.
+package netrace
+
+const (
+	TypeJoin   = "join"
+	TypeLobby  = "lobby"
+	TypeStart  = "start"
+	TypeState  = "state"
+	TypeGoal   = "goal"
+	TypeWinner = "winner"
+)
+
+// Message is a message between a server and a client.
+type Message struct {
+	Type string `json:"type"`
+
+	// ID is the player's ID.
+	// For a start message, ID is the receiver's ID.
+	ID int `json:"id"`
+
+	// Name is the player's name for join and winner messages.
+	Name string `json:"name,omitempty"`
+
+	// Players are the names of the players for lobby and start messages.
+	Players []string `json:"players,omitempty"`
+
+	// Required is the number of players to start a race for a lobby message.
+	Required int `json:"required,omitempty"`
+
+	// Difficulty and Seed specify the building for a start message.
+	Difficulty int    `json:"difficulty,omitempty"`
+	Seed       uint64 `json:"seed,omitempty"`
+
+	// State is the player's state for a state message.
+	State *PlayerState `json:"state,omitempty"`
+}
+
+// PlayerState is a player's position and depth state.
+type PlayerState struct {
+	// X and Y are the position in pixels. The Y axis points upward.
+	X int `json:"x"`
+	Y int `json:"y"`
+
+	Depth0 int `json:"depth0"`
+	Depth1 int `json:"depth1"`
+}
@@ -0,0 +1,253 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package netrace
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net"
+	"sync"
+)
+
+// Server is a race server.
+// Players who connect to the server wait in the lobby, and a race starts when enough players join.
+type Server struct {
+	// Players is the number of players to start a race.
+	Players int
+
+	// Difficulty is the difficulty of the buildings.
+	Difficulty int
+
+	// Seed returns a seed for a new race.
+	// If Seed is nil, a random seed is used.
+	Seed func() uint64
+
+	// Logger is used to log events.
+	// If Logger is nil, the events are not logged.
+	Logger *log.Logger
+
+	m     sync.Mutex
+	lobby []*conn
+}
+
+type conn struct {
+	conn     net.Conn
+	name     string
+	scanner  *bufio.Scanner
+	encoder  *json.Encoder
+	messages chan *Message
+	m        sync.Mutex
+}
+
+func (c *conn) send(msg *Message) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+	return c.encoder.Encode(msg)
+}
+
+// Serve accepts connections on the listener and runs races until the listener is closed.
+func (s *Server) Serve(l net.Listener) error {
+	if s.Players <= 0 {
+		return fmt.Errorf("netrace: the number of players must be positive but %d", s.Players)
+	}
+	for {
+		c, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go s.handle(c)
+	}
+}
+
+func (s *Server) logf(format string, args ...any) {
+	if s.Logger == nil {
+		return
+	}
+	s.Logger.Printf(format, args...)
+}
+
+func (s *Server) handle(c net.Conn) {
+	scanner := bufio.NewScanner(c)
+	var join Message
+	if !scanner.Scan() {
+		_ = c.Close()
+		return
+	}
+	if err := json.Unmarshal(scanner.Bytes(), &join); err != nil || join.Type != TypeJoin {
+		s.logf("invalid join message from %s", c.RemoteAddr())
+		_ = c.Close()
+		return
+	}
+
+	cn := &conn{
+		conn:     c,
+		name:     join.Name,
+		scanner:  scanner,
+		encoder:  json.NewEncoder(c),
+		messages: make(chan *Message, 64),
+	}
+	s.logf("%s (%s) joined the lobby", cn.name, c.RemoteAddr())
+
+	s.m.Lock()
+	s.lobby = append(s.lobby, cn)
+	var conns []*conn
+	if len(s.lobby) >= s.Players {
+		conns = s.lobby[:s.Players]
+		s.lobby = s.lobby[s.Players:]
+	}
+	lobby := append([]*conn{}, s.lobby...)
+	s.m.Unlock()
+
+	go s.read(cn)
+
+	if conns == nil {
+		s.broadcastLobby(lobby)
+		return
+	}
+	s.runRace(conns)
+}
+
+// read reads the messages from the connection until it is closed.
+// read is the only reader of the connection both in the lobby and in a race,
+// so that a player leaving the lobby is noticed.
+func (s *Server) read(c *conn) {
+	defer func() {
+		close(c.messages)
+		s.leaveLobby(c)
+	}()
+	for c.scanner.Scan() {
+		var msg Message
+		if err := json.Unmarshal(c.scanner.Bytes(), &msg); err != nil {
+			s.logf("invalid message from %s: %v", c.name, err)
+			return
+		}
+		c.messages <- &msg
+	}
+}
+
+// leaveLobby removes the connection from the lobby if it is waiting there, and tells the others.
+func (s *Server) leaveLobby(c *conn) {
+	s.m.Lock()
+	idx := -1
+	for i, cn := range s.lobby {
+		if cn == c {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		s.m.Unlock()
+		return
+	}
+	s.lobby = append(s.lobby[:idx], s.lobby[idx+1:]...)
+	lobby := append([]*conn{}, s.lobby...)
+	s.m.Unlock()
+
+	s.logf("%s (%s) left the lobby", c.name, c.conn.RemoteAddr())
+	_ = c.conn.Close()
+	s.broadcastLobby(lobby)
+}
+
+func (s *Server) broadcastLobby(conns []*conn) {
+	msg := &Message{
+		Type:     TypeLobby,
+		Required: s.Players,
+	}
+	for _, c := range conns {
+		msg.Players = append(msg.Players, c.name)
+	}
+	for _, c := range conns {
+		_ = c.send(msg)
+	}
+}
+
+type race struct {
+	conns  []*conn
+	m      sync.Mutex
+	winner int
+}
+
+// runRace runs a race among the connections.
+func (s *Server) runRace(conns []*conn) {
+	seed := rand.Uint64()
+	if s.Seed != nil {
+		seed = s.Seed()
+	}
+
+	var names []string
+	for _, c := range conns {
+		names = append(names, c.name)
+	}
+	s.logf("a race started among %v (seed: %d)", names, seed)
+
+	r := &race{
+		conns:  conns,
+		winner: -1,
+	}
+	for i, c := range conns {
+		_ = c.send(&Message{
+			Type:       TypeStart,
+			ID:         i,
+			Players:    names,
+			Difficulty: s.Difficulty,
+			Seed:       seed,
+		})
+	}
+
+	var wg sync.WaitGroup
+	for i, c := range conns {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.relay(r, i)
+			_ = c.conn.Close()
+			// Drain the messages so that the reader finishes.
+			for range c.messages {
+			}
+		}()
+	}
+	wg.Wait()
+	s.logf("a race among %v finished", names)
+}
+
+func (s *Server) relay(r *race, id int) {
+	for msg := range r.conns[id].messages {
+		msg.ID = id
+		switch msg.Type {
+		case TypeState:
+			for i, c := range r.conns {
+				if i == id {
+					continue
+				}
+				_ = c.send(msg)
+			}
+		case TypeGoal:
+			r.m.Lock()
+			first := r.winner < 0
+			if first {
+				r.winner = id
+			}
+			r.m.Unlock()
+			if !first {
+				continue
+			}
+			s.logf("%s won the race", r.conns[id].name)
+			for _, c := range r.conns {
+				_ = c.send(&Message{
+					Type: TypeWinner,
+					ID:   id,
+					Name: r.conns[id].name,
+				})
+			}
+			// The race is over. Close all the connections.
+			for _, c := range r.conns {
+				_ = c.conn.Close()
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,199 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package netrace_test
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/sugoimaze/internal/netrace"
+)
+
+const testTimeout = 5 * time.Second
+
+func startServer(t *testing.T, s *netrace.Server) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = l.Close()
+	})
+	go func() {
+		_ = s.Serve(l)
+	}()
+	return l.Addr().String()
+}
+
+func dial(t *testing.T, addr string, name string) *netrace.Client {
+	t.Helper()
+	c, err := netrace.Dial(addr, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = c.Close()
+	})
+	return c
+}
+
+// receive returns the first message that satisfies f, skipping the others.
+func receive(t *testing.T, c *netrace.Client, f func(msg *netrace.Message) bool) *netrace.Message {
+	t.Helper()
+	timeout := time.After(testTimeout)
+	for {
+		select {
+		case msg, ok := <-c.Messages():
+			if !ok {
+				t.Fatalf("the connection was closed: %v", c.Err())
+			}
+			if f(msg) {
+				return msg
+			}
+		case <-timeout:
+			t.Fatal("timeout")
+		}
+	}
+}
+
+func ofType(typ string) func(msg *netrace.Message) bool {
+	return func(msg *netrace.Message) bool {
+		return msg.Type == typ
+	}
+}
+
+func TestRace(t *testing.T) {
+	const (
+		players    = 3
+		difficulty = 2
+		seed       = 12345
+	)
+	addr := startServer(t, &netrace.Server{
+		Players:    players,
+		Difficulty: difficulty,
+		Seed: func() uint64 {
+			return seed
+		},
+	})
+
+	var clients []*netrace.Client
+	for i := range players {
+		clients = append(clients, dial(t, addr, fmt.Sprintf("player%d", i)))
+	}
+
+	// All the clients start the same building with different IDs.
+	ids := map[int]int{}
+	for i, c := range clients {
+		msg := receive(t, c, ofType(netrace.TypeStart))
+		if msg.Seed != seed {
+			t.Errorf("client %d: seed: got: %d, want: %d", i, msg.Seed, seed)
+		}
+		if msg.Difficulty != difficulty {
+			t.Errorf("client %d: difficulty: got: %d, want: %d", i, msg.Difficulty, difficulty)
+		}
+		if len(msg.Players) != players {
+			t.Errorf("client %d: players: got: %v, want: %d players", i, msg.Players, players)
+		}
+		if msg.ID < 0 || msg.ID >= players || msg.Players[msg.ID] != fmt.Sprintf("player%d", i) {
+			t.Errorf("client %d: ID %d doesn't match the players %v", i, msg.ID, msg.Players)
+		}
+		if j, ok := ids[msg.ID]; ok {
+			t.Errorf("client %d and %d: the same ID %d", j, i, msg.ID)
+		}
+		ids[msg.ID] = i
+	}
+	idOf := func(client int) int {
+		for id, i := range ids {
+			if i == client {
+				return id
+			}
+		}
+		panic("not reached")
+	}
+
+	// A state is relayed to the other players with the sender's ID.
+	state := netrace.PlayerState{X: 32, Y: 48, Depth0: 1, Depth1: 0}
+	if err := clients[0].SendState(state); err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range clients[1:] {
+		msg := receive(t, c, ofType(netrace.TypeState))
+		if msg.ID != idOf(0) {
+			t.Errorf("client %d: state ID: got: %d, want: %d", i+1, msg.ID, idOf(0))
+		}
+		if msg.State == nil || *msg.State != state {
+			t.Errorf("client %d: state: got: %v, want: %v", i+1, msg.State, state)
+		}
+	}
+
+	// The first player to reach the goal is announced to everyone.
+	if err := clients[1].SendGoal(); err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range clients {
+		msg := receive(t, c, ofType(netrace.TypeWinner))
+		if msg.ID != idOf(1) || msg.Name != "player1" {
+			t.Errorf("client %d: winner: got: %d (%s), want: %d (player1)", i, msg.ID, msg.Name, idOf(1))
+		}
+	}
+
+	// The server closes the connections after the race.
+	for i, c := range clients {
+		timeout := time.After(testTimeout)
+	loop:
+		for {
+			select {
+			case _, ok := <-c.Messages():
+				if !ok {
+					break loop
+				}
+			case <-timeout:
+				t.Fatalf("client %d: the connection was not closed", i)
+			}
+		}
+	}
+}
+
+func TestLobbyLeave(t *testing.T) {
+	addr := startServer(t, &netrace.Server{
+		Players: 3,
+	})
+
+	a := dial(t, addr, "a")
+	receive(t, a, ofType(netrace.TypeLobby))
+	b := dial(t, addr, "b")
+	receive(t, b, func(msg *netrace.Message) bool {
+		return msg.Type == netrace.TypeLobby && len(msg.Players) == 2
+	})
+
+	// The lobby is updated when a waiting player leaves.
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	msg := receive(t, b, func(msg *netrace.Message) bool {
+		return msg.Type == netrace.TypeLobby && len(msg.Players) == 1
+	})
+	if !slices.Equal(msg.Players, []string{"b"}) {
+		t.Errorf("players: got: %v, want: [b]", msg.Players)
+	}
+	if msg.Required != 3 {
+		t.Errorf("required: got: %d, want: 3", msg.Required)
+	}
+
+	// The player who left doesn't take a place in the next race.
+	c := dial(t, addr, "c")
+	d := dial(t, addr, "d")
+	for _, cl := range []*netrace.Client{b, c, d} {
+		msg := receive(t, cl, ofType(netrace.TypeStart))
+		players := slices.Clone(msg.Players)
+		slices.Sort(players)
+		if !slices.Equal(players, []string{"b", "c", "d"}) {
+			t.Errorf("players: got: %v, want: [b c d]", msg.Players)
+		}
+	}
+}
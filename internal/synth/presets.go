@@ -0,0 +1,31 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package synth
+
+// Presets are the sound effects of the game by names.
+var Presets = map[string]Sound{
+	"footstep": {
+		{Waveform: Noise, Frequency: 6000, Volume: 0.25, Length: 0.03, Envelope: Envelope{Decay: 0.03}},
+	},
+	"ladder": {
+		{Waveform: Square, Frequency: 1200, Duty: 0.25, Volume: 0.15, Length: 0.02, Envelope: Envelope{Decay: 0.02}},
+		{Waveform: Square, Frequency: 900, Duty: 0.25, Volume: 0.15, Delay: 0.06, Length: 0.02, Envelope: Envelope{Decay: 0.02}},
+	},
+	"switch": {
+		{Waveform: Square, Frequency: 880, FrequencyEnd: 1760, Duty: 0.25, Volume: 0.2, Length: 0.08, Envelope: Envelope{Sustain: 1, Release: 0.04}},
+	},
+	"door": {
+		{Waveform: Triangle, Frequency: 110, FrequencyEnd: 70, Volume: 0.5, Length: 0.25, Envelope: Envelope{Attack: 0.01, Decay: 0.24}},
+		{Waveform: Noise, Frequency: 2000, Volume: 0.15, Length: 0.2, Envelope: Envelope{Attack: 0.02, Decay: 0.18}},
+	},
+	"blocked": {
+		{Waveform: Square, Frequency: 150, FrequencyEnd: 100, Volume: 0.2, Length: 0.12, Envelope: Envelope{Sustain: 1, Release: 0.03}},
+	},
+	"goal": {
+		{Waveform: Triangle, Frequency: NoteFrequency(72), Volume: 0.4, Length: 0.08, Envelope: Envelope{Sustain: 1, Release: 0.02}},
+		{Waveform: Triangle, Frequency: NoteFrequency(76), Volume: 0.4, Delay: 0.1, Length: 0.08, Envelope: Envelope{Sustain: 1, Release: 0.02}},
+		{Waveform: Triangle, Frequency: NoteFrequency(79), Volume: 0.4, Delay: 0.2, Length: 0.08, Envelope: Envelope{Sustain: 1, Release: 0.02}},
+		{Waveform: Triangle, Frequency: NoteFrequency(84), Volume: 0.4, Delay: 0.3, Length: 0.3, Envelope: Envelope{Decay: 0.3, Sustain: 0.3, Release: 0.1}},
+	},
+}
@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package synth
+
+// Sequence is a loop of notes played by an oscillator.
+type Sequence struct {
+	// Notes are MIDI note numbers for each step. 0 is a rest.
+	Notes []int
+
+	// Tempo is the number of beats per minute.
+	Tempo int
+
+	// StepsPerBeat is the number of steps in a beat.
+	StepsPerBeat int
+
+	Waveform Waveform
+	Duty     float64
+	Volume   float64
+
+	// Envelope is applied to each note. A note is held for a step.
+	Envelope Envelope
+}
+
+// SequenceStream is an infinite stream of a sequence in 16-bit little-endian stereo PCM.
+type SequenceStream struct {
+	sequence   Sequence
+	oscillator *oscillator
+	pos        int
+}
+
+// NewStream returns a new stream looping the sequence.
+func (s *Sequence) NewStream() *SequenceStream {
+	return &SequenceStream{
+		sequence:   *s,
+		oscillator: newOscillator(s.Waveform, s.Duty),
+	}
+}
+
+// Read implements io.Reader.
+func (s *SequenceStream) Read(buf []byte) (int, error) {
+	seq := &s.sequence
+	stepSamples := SampleRate * 60 / seq.Tempo / seq.StepsPerBeat
+	stepLength := float64(stepSamples) / SampleRate
+	n := len(buf) / bytesPerSample * bytesPerSample
+	for i := 0; i < n; i += bytesPerSample {
+		step := s.pos / stepSamples
+		t := float64(s.pos%stepSamples) / SampleRate
+		var v float64
+		if note := seq.Notes[step]; note != 0 {
+			v = s.oscillator.next(NoteFrequency(note)) * seq.Envelope.gain(t, stepLength) * seq.Volume
+		}
+		putSample(buf[i:], v)
+		s.pos = (s.pos + 1) % (stepSamples * len(seq.Notes))
+	}
+	return n, nil
+}
@@ -0,0 +1,185 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+// Package synth is a small synthesizer that generates 16-bit stereo PCM at 48 kHz.
+package synth
+
+import (
+	"math"
+)
+
+// SampleRate is the sample rate of the generated PCM.
+const SampleRate = 48000
+
+// bytesPerSample is the size of a sample in bytes: 16-bit stereo.
+const bytesPerSample = 4
+
+// Waveform is the kind of an oscillator.
+type Waveform int
+
+const (
+	Square Waveform = iota
+	Triangle
+	Noise
+)
+
+// Envelope is an ADSR envelope. The times are in seconds.
+type Envelope struct {
+	Attack  float64
+	Decay   float64
+	Sustain float64
+	Release float64
+}
+
+// gain returns the gain at t seconds for a note held for length seconds.
+func (e Envelope) gain(t, length float64) float64 {
+	if t < 0 {
+		return 0
+	}
+	if t >= length {
+		if e.Release <= 0 {
+			return 0
+		}
+		return max(0, e.gain(length-1e-9, length)*(1-(t-length)/e.Release))
+	}
+	if t < e.Attack {
+		return t / e.Attack
+	}
+	t -= e.Attack
+	if t < e.Decay {
+		return 1 - (1-e.Sustain)*t/e.Decay
+	}
+	return e.Sustain
+}
+
+// Voice is a note played by an oscillator.
+type Voice struct {
+	Waveform Waveform
+
+	// Frequency is the frequency in Hz at the start.
+	// FrequencyEnd is the frequency at the end of the note to slide to. 0 means no slide.
+	Frequency    float64
+	FrequencyEnd float64
+
+	// Duty is the duty cycle of a square wave. 0 means 0.5.
+	Duty float64
+
+	Volume   float64
+	Envelope Envelope
+
+	// Delay is the time to start the note in seconds.
+	Delay float64
+
+	// Length is the time to hold the note in seconds, not including the release.
+	Length float64
+}
+
+func (v *Voice) duration() float64 {
+	return v.Delay + v.Length + v.Envelope.Release
+}
+
+// oscillator generates a waveform with its phase.
+type oscillator struct {
+	waveform Waveform
+	duty     float64
+	phase    float64
+	noise    uint32
+	value    float64
+}
+
+func newOscillator(waveform Waveform, duty float64) *oscillator {
+	if duty == 0 {
+		duty = 0.5
+	}
+	return &oscillator{
+		waveform: waveform,
+		duty:     duty,
+		// A fixed seed makes noises deterministic.
+		noise: 0xace1,
+	}
+}
+
+// next returns the current value from -1 to 1 and advances the phase by the frequency.
+func (o *oscillator) next(freq float64) float64 {
+	var v float64
+	switch o.waveform {
+	case Square:
+		v = -1
+		if o.phase < o.duty {
+			v = 1
+		}
+	case Triangle:
+		if o.phase < 0.5 {
+			v = 4*o.phase - 1
+		} else {
+			v = 3 - 4*o.phase
+		}
+	case Noise:
+		// The noise changes its value at the frequency, like retro sound chips.
+		v = o.value
+	}
+	o.phase += freq / SampleRate
+	if o.phase >= 1 {
+		o.phase -= math.Floor(o.phase)
+		if o.waveform == Noise {
+			// 16-bit Galois LFSR.
+			lsb := o.noise & 1
+			o.noise >>= 1
+			if lsb != 0 {
+				o.noise ^= 0xb400
+			}
+			o.value = float64(o.noise&1)*2 - 1
+		}
+	}
+	return v
+}
+
+// Sound is a set of voices mixed together, such as a sound effect.
+type Sound []Voice
+
+// Samples returns the mixed samples of the sound from -1 to 1.
+func (s Sound) Samples() []float64 {
+	var d float64
+	for i := range s {
+		d = max(d, s[i].duration())
+	}
+	samples := make([]float64, int(math.Ceil(d*SampleRate)))
+	for i := range s {
+		v := &s[i]
+		o := newOscillator(v.Waveform, v.Duty)
+		start := int(v.Delay * SampleRate)
+		end := min(int(math.Ceil(v.duration()*SampleRate)), len(samples))
+		for j := start; j < end; j++ {
+			t := float64(j-start) / SampleRate
+			freq := v.Frequency
+			if v.FrequencyEnd != 0 && v.Length > 0 {
+				freq += (v.FrequencyEnd - v.Frequency) * min(t/v.Length, 1)
+			}
+			samples[j] += o.next(freq) * v.Envelope.gain(t, v.Length) * v.Volume
+		}
+	}
+	return samples
+}
+
+// PCM returns the sound as 16-bit little-endian stereo PCM.
+func (s Sound) PCM() []byte {
+	samples := s.Samples()
+	buf := make([]byte, len(samples)*bytesPerSample)
+	for i, v := range samples {
+		putSample(buf[i*bytesPerSample:], v)
+	}
+	return buf
+}
+
+func putSample(buf []byte, v float64) {
+	s := int16(min(max(v, -1), 1) * math.MaxInt16)
+	buf[0] = byte(s)
+	buf[1] = byte(s >> 8)
+	buf[2] = byte(s)
+	buf[3] = byte(s >> 8)
+}
+
+// NoteFrequency returns the frequency of the MIDI note number in Hz.
+func NoteFrequency(note int) float64 {
+	return 440 * math.Pow(2, float64(note-69)/12)
+}
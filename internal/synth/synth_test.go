@@ -0,0 +1,282 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package synth
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestSamplesLength(t *testing.T) {
+	testCases := []struct {
+		name  string
+		sound Sound
+		want  int
+	}{
+		{
+			name:  "empty",
+			sound: Sound{},
+			want:  0,
+		},
+		{
+			name: "delay and release",
+			sound: Sound{
+				{Waveform: Square, Frequency: 440, Volume: 1, Delay: 0.25, Length: 0.5, Envelope: Envelope{Sustain: 1, Release: 0.125}},
+			},
+			want: 42000,
+		},
+		{
+			name: "longest voice",
+			sound: Sound{
+				{Waveform: Square, Frequency: 440, Volume: 1, Length: 0.5},
+				{Waveform: Triangle, Frequency: 440, Volume: 1, Delay: 0.5, Length: 0.25},
+			},
+			want: 36000,
+		},
+		{
+			name: "rounded up",
+			sound: Sound{
+				{Waveform: Noise, Frequency: 440, Volume: 1, Length: 1.0 / 96000},
+			},
+			want: 1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := len(tc.sound.Samples()); got != tc.want {
+				t.Errorf("len(Samples()): got: %d, want: %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEnvelopeGain(t *testing.T) {
+	e := Envelope{Attack: 0.125, Decay: 0.25, Sustain: 0.5, Release: 0.5}
+	const length = 1
+	testCases := []struct {
+		t    float64
+		want float64
+	}{
+		{t: -0.1, want: 0},
+		{t: 0, want: 0},
+		{t: 0.0625, want: 0.5},
+		{t: 0.125, want: 1},
+		{t: 0.25, want: 0.75},
+		{t: 0.375, want: 0.5},
+		{t: 0.75, want: 0.5},
+		{t: 1.25, want: 0.25},
+		{t: 1.5, want: 0},
+		{t: 2, want: 0},
+	}
+	for _, tc := range testCases {
+		if got := e.gain(tc.t, length); math.Abs(got-tc.want) > 1e-6 {
+			t.Errorf("gain(%v, %v): got: %v, want: %v", tc.t, length, got, tc.want)
+		}
+	}
+
+	// Without a release, the note stops at the end of the length.
+	e = Envelope{Sustain: 1}
+	if got := e.gain(0.5, length); got != 1 {
+		t.Errorf("gain(0.5, %v) without a release: got: %v, want: 1", length, got)
+	}
+	if got := e.gain(length, length); got != 0 {
+		t.Errorf("gain(%v, %v) without a release: got: %v, want: 0", length, length, got)
+	}
+}
+
+func TestOscillator(t *testing.T) {
+	// 480 Hz is 100 samples per period.
+	const freq = 480
+
+	testCases := []struct {
+		name     string
+		waveform Waveform
+		duty     float64
+		wantHigh float64
+	}{
+		{name: "square", waveform: Square, duty: 0, wantHigh: 0.5},
+		{name: "square with duty 0.25", waveform: Square, duty: 0.25, wantHigh: 0.25},
+		{name: "triangle", waveform: Triangle, duty: 0, wantHigh: 0.5},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			o := newOscillator(tc.waveform, tc.duty)
+			var rises, highs int
+			var prev float64
+			for i := range SampleRate {
+				v := o.next(freq)
+				if v < -1 || v > 1 {
+					t.Fatalf("next() at %d: got: %v, want: [-1, 1]", i, v)
+				}
+				if v > 0 {
+					highs++
+				}
+				if i > 0 && prev <= 0 && v > 0 {
+					rises++
+				}
+				prev = v
+			}
+			// A second has freq periods. The first period doesn't start with a rise.
+			if rises < freq-1 || rises > freq {
+				t.Errorf("rises in a second: got: %d, want: %d", rises, freq)
+			}
+			if got := float64(highs) / SampleRate; math.Abs(got-tc.wantHigh) > 0.02 {
+				t.Errorf("ratio of positive values: got: %v, want: %v", got, tc.wantHigh)
+			}
+		})
+	}
+}
+
+func TestTrianglePeriod(t *testing.T) {
+	// 480 Hz is 100 samples per period: -1 at the start, 1 at the half.
+	o := newOscillator(Triangle, 0)
+	samples := make([]float64, 200)
+	for i := range samples {
+		samples[i] = o.next(480)
+	}
+	for _, i := range []int{0, 100} {
+		if math.Abs(samples[i]+1) > 1e-6 {
+			t.Errorf("samples[%d]: got: %v, want: -1", i, samples[i])
+		}
+	}
+	for _, i := range []int{50, 150} {
+		if math.Abs(samples[i]-1) > 1e-6 {
+			t.Errorf("samples[%d]: got: %v, want: 1", i, samples[i])
+		}
+	}
+}
+
+func TestNoiseDeterministic(t *testing.T) {
+	s := Sound{
+		{Waveform: Noise, Frequency: 4000, Volume: 1, Length: 0.1, Envelope: Envelope{Sustain: 1}},
+	}
+	s0 := s.Samples()
+	s1 := s.Samples()
+	if !slices.Equal(s0, s1) {
+		t.Errorf("Samples() differs between calls")
+	}
+
+	var pos, neg int
+	for _, v := range s0 {
+		if v > 0 {
+			pos++
+		}
+		if v < 0 {
+			neg++
+		}
+	}
+	if pos == 0 || neg == 0 {
+		t.Errorf("noise is constant: positive: %d, negative: %d", pos, neg)
+	}
+}
+
+func TestPCM(t *testing.T) {
+	testCases := []struct {
+		name  string
+		sound Sound
+		want  int16
+	}{
+		{
+			name: "half",
+			sound: Sound{
+				{Waveform: Square, Frequency: 1, Volume: 0.5, Length: 0.01, Envelope: Envelope{Sustain: 1}},
+			},
+			want: math.MaxInt16 / 2,
+		},
+		{
+			name: "clamped",
+			sound: Sound{
+				{Waveform: Square, Frequency: 1, Volume: 1, Length: 0.01, Envelope: Envelope{Sustain: 1}},
+				{Waveform: Square, Frequency: 1, Volume: 1, Length: 0.01, Envelope: Envelope{Sustain: 1}},
+			},
+			want: math.MaxInt16,
+		},
+		{
+			name: "negative",
+			sound: Sound{
+				// The phase starts in the low part of the square wave.
+				{Waveform: Square, Frequency: 1, Duty: 1e-9, Volume: 1, Length: 0.01, Envelope: Envelope{Sustain: 1}},
+			},
+			want: -math.MaxInt16,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pcm := tc.sound.PCM()
+			if got, want := len(pcm), len(tc.sound.Samples())*4; got != want {
+				t.Fatalf("len(PCM()): got: %d, want: %d", got, want)
+			}
+			// The second sample is checked as the first sample of a square wave with a tiny duty is high.
+			buf := pcm[4:8]
+			l := int16(binary.LittleEndian.Uint16(buf[0:2]))
+			r := int16(binary.LittleEndian.Uint16(buf[2:4]))
+			if l != tc.want || r != tc.want {
+				t.Errorf("PCM()[4:8]: got: (%d, %d), want: (%d, %d)", l, r, tc.want, tc.want)
+			}
+		})
+	}
+}
+
+func TestSequenceStreamLoop(t *testing.T) {
+	// 440 Hz and 880 Hz have whole periods in a step, so the oscillator's phase is back at the start after a loop.
+	seq := &Sequence{
+		Notes:        []int{69, 0, 81, 0},
+		Tempo:        120,
+		StepsPerBeat: 4,
+		Waveform:     Triangle,
+		Volume:       0.5,
+		Envelope:     Envelope{Sustain: 1},
+	}
+	const stepSamples = SampleRate * 60 / 120 / 4
+	loopBytes := stepSamples * len(seq.Notes) * bytesPerSample
+
+	s := seq.NewStream()
+	buf := make([]byte, 2*loopBytes)
+	if _, err := io.ReadFull(s, buf); err != nil {
+		t.Fatal(err)
+	}
+	if s.pos != 0 {
+		t.Errorf("pos after two loops: got: %d, want: 0", s.pos)
+	}
+
+	for i := 0; i < loopBytes; i += 2 {
+		v0 := int16(binary.LittleEndian.Uint16(buf[i:]))
+		v1 := int16(binary.LittleEndian.Uint16(buf[loopBytes+i:]))
+		if d := int(v0) - int(v1); d < -64 || d > 64 {
+			t.Fatalf("sample at byte %d: first loop: %d, second loop: %d", i, v0, v1)
+		}
+	}
+
+	// The rests are silent.
+	for step := 1; step < len(seq.Notes); step += 2 {
+		for i := step * stepSamples * bytesPerSample; i < (step+1)*stepSamples*bytesPerSample; i++ {
+			if buf[i] != 0 {
+				t.Fatalf("byte %d in the rest at step %d: got: %d, want: 0", i, step, buf[i])
+			}
+		}
+	}
+}
+
+func TestSequenceStreamPartialRead(t *testing.T) {
+	seq := &Sequence{
+		Notes:        []int{69},
+		Tempo:        120,
+		StepsPerBeat: 4,
+		Waveform:     Square,
+		Volume:       0.5,
+		Envelope:     Envelope{Sustain: 1},
+	}
+	s := seq.NewStream()
+	// Only whole samples are read.
+	n, err := s.Read(make([]byte, 7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 4 {
+		t.Errorf("Read with 7 bytes: got: %d, want: 4", n)
+	}
+}
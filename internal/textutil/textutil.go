@@ -0,0 +1,66 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+// Package textutil draws texts with the embedded font, which covers both English and Japanese.
+package textutil
+
+import (
+	"bytes"
+	_ "embed"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+//go:embed mplus-1p-regular.ttf
+var mplus1pRegularTTF []byte
+
+// LineHeight is the height of a line in pixels.
+const LineHeight = 16
+
+var (
+	face        *text.GoTextFace
+	shadowColor = color.RGBA{0, 0, 0, 0xc0}
+)
+
+func init() {
+	src, err := text.NewGoTextFaceSource(bytes.NewReader(mplus1pRegularTTF))
+	if err != nil {
+		panic(err)
+	}
+	face = &text.GoTextFace{
+		Source: src,
+		Size:   12,
+	}
+}
+
+// Print draws the string at the upper-left corner of the image.
+func Print(dst *ebiten.Image, str string) {
+	PrintAt(dst, str, 0, 0)
+}
+
+// PrintAt draws the string with a shadow. (x, y) is the upper-left corner of the first line.
+func PrintAt(dst *ebiten.Image, str string, x, y int) {
+	// Center the glyphs vertically in each line.
+	m := face.Metrics()
+	top := math.Round((LineHeight - m.HAscent - m.HDescent) / 2)
+
+	op := &text.DrawOptions{}
+	op.LineSpacing = LineHeight
+	op.GeoM.Translate(float64(x+1), float64(y+1)+top)
+	op.ColorScale.ScaleWithColor(shadowColor)
+	text.Draw(dst, str, face, op)
+
+	op.GeoM.Reset()
+	op.GeoM.Translate(float64(x), float64(y)+top)
+	op.ColorScale.Reset()
+	text.Draw(dst, str, face, op)
+}
+
+// Width returns the width of the longest line of the string in pixels.
+func Width(str string) int {
+	w, _ := text.Measure(str, face, LineHeight)
+	return int(math.Ceil(w))
+}
@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package main
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"github.com/hajimehoshi/sugoimaze/internal/game"
+	"github.com/hajimehoshi/sugoimaze/internal/lang"
+	"github.com/hajimehoshi/sugoimaze/internal/textutil"
+)
+
+const leaderboardSize = 7
+
+// LeaderboardScene shows the fastest records of each building and speed.
+type LeaderboardScene struct {
+	difficulty game.Difficulty
+	speed      game.Speed
+
+	// seeds are the buildings of the difficulty with records. seedIndex is the index of the shown one.
+	seeds     []uint64
+	seedIndex int
+	records   []Record
+	loaded    bool
+}
+
+func (l *LeaderboardScene) Update(gameContext GameContext) error {
+	if inpututil.IsKeyJustPressed(ebiten.KeyLeft) || inpututil.IsKeyJustPressed(ebiten.KeyA) {
+		if l.difficulty > game.LevelTutorial {
+			l.difficulty--
+			l.seeds = nil
+			l.loaded = false
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyRight) || inpututil.IsKeyJustPressed(ebiten.KeyD) {
+		if l.difficulty < game.LevelSugoi {
+			l.difficulty++
+			l.seeds = nil
+			l.loaded = false
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyUp) || inpututil.IsKeyJustPressed(ebiten.KeyW) {
+		if l.speed < game.SpeedFast {
+			l.speed++
+			l.loaded = false
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyDown) || inpututil.IsKeyJustPressed(ebiten.KeyS) {
+		if l.speed > game.SpeedSlow {
+			l.speed--
+			l.loaded = false
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyQ) {
+		if l.seedIndex > 0 {
+			l.seedIndex--
+			l.loaded = false
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyE) {
+		if l.seedIndex < len(l.seeds)-1 {
+			l.seedIndex++
+			l.loaded = false
+		}
+	}
+	if !l.loaded {
+		if l.seeds == nil {
+			seeds, err := RecordSeeds(l.difficulty)
+			if err != nil {
+				return err
+			}
+			l.seeds = seeds
+			l.seedIndex = 0
+		}
+		l.records = nil
+		if len(l.seeds) > 0 {
+			records, err := LoadRecords(l.difficulty, l.seeds[l.seedIndex], l.speed)
+			if err != nil {
+				return err
+			}
+			l.records = records
+		}
+		l.loaded = true
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeySpace) || inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		gameContext.GoToTitle()
+	}
+	return nil
+}
+
+func (l *LeaderboardScene) Draw(screen *ebiten.Image) {
+	screen.Fill(color.RGBA{0, 0, 0, 255})
+
+	msg := lang.Sprintf("Leaderboard: < %s >", lang.T(l.difficulty.String())) + "\n"
+	msg += lang.Sprintf("Speed: %s", lang.T(l.speed.String())) + "\n"
+	if len(l.seeds) > 0 {
+		msg += lang.Sprintf("Building: < Seed %d > (%d/%d)", l.seeds[l.seedIndex], l.seedIndex+1, len(l.seeds))
+	}
+	msg += "\n\n"
+	if len(l.records) == 0 {
+		msg += lang.T("No records yet.") + "\n"
+	}
+	for i, r := range l.records[:min(len(l.records), leaderboardSize)] {
+		msg += lang.Sprintf("%d. %s  %d steps  %d switches  %s", i+1, game.FormatTicks(r.Ticks), r.Steps, r.SwitchPresses, r.Date.Format(time.DateOnly)) + "\n"
+	}
+	msg += "\n" + lang.T("Left, Right: Difficulty") + "\n" + lang.T("Up, Down: Speed") + "\n" + lang.T("Q, E: Building") + "\n" + lang.T("Space, Enter: Title")
+	textutil.Print(screen, msg)
+}
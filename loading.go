@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package main
+
+import (
+	"context"
+	"image/color"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"github.com/hajimehoshi/sugoimaze/internal/game"
+	"github.com/hajimehoshi/sugoimaze/internal/lang"
+	"github.com/hajimehoshi/sugoimaze/internal/textutil"
+)
+
+// minLoadingDuration is the minimum duration to show the loading screen.
+const minLoadingDuration = time.Second
+
+// levelLoader generates a building on another goroutine.
+type levelLoader struct {
+	cancelFunc context.CancelFunc
+
+	// done is closed when the generation finishes or is canceled.
+	done chan struct{}
+
+	// data is written before done is closed, and is nil if the generation is canceled.
+	data *game.FieldData
+
+	// canceled is set by cancel so that fieldData doesn't return a result finished after the cancellation.
+	canceled atomic.Bool
+
+	currentProgress game.Progress
+	progressM       sync.Mutex
+}
+
+// newLevelLoader starts to generate a building.
+func newLevelLoader(difficulty game.Difficulty, seed uint64) *levelLoader {
+	ctx, cancel := context.WithCancel(context.Background())
+	l := &levelLoader{
+		cancelFunc: cancel,
+		done:       make(chan struct{}),
+	}
+	start := time.Now()
+	go func() {
+		defer close(l.done)
+		data, err := game.GenerateFieldData(ctx, difficulty, seed, l.setProgress)
+		if err != nil {
+			return
+		}
+		// Wait to show the loading screen at least for minLoadingDuration.
+		t := time.NewTimer(minLoadingDuration - time.Since(start))
+		defer t.Stop()
+		select {
+		case <-t.C:
+			l.data = data
+		case <-ctx.Done():
+		}
+	}()
+	return l
+}
+
+func (l *levelLoader) setProgress(progress game.Progress) {
+	l.progressM.Lock()
+	defer l.progressM.Unlock()
+	l.currentProgress = progress
+}
+
+// progress returns the current progress of the generation.
+func (l *levelLoader) progress() game.Progress {
+	l.progressM.Lock()
+	defer l.progressM.Unlock()
+	return l.currentProgress
+}
+
+// fieldData returns the generated building, or nil if the generation is in progress or canceled.
+func (l *levelLoader) fieldData() *game.FieldData {
+	if l.canceled.Load() {
+		return nil
+	}
+	select {
+	case <-l.done:
+		return l.data
+	default:
+		return nil
+	}
+}
+
+// cancel stops the generation without waiting for the goroutine, which drops its result by itself.
+// fieldData always returns nil after cancel is called.
+func (l *levelLoader) cancel() {
+	l.cancelFunc()
+	l.canceled.Store(true)
+}
+
+const (
+	progressBarWidth  = 200
+	progressBarHeight = 8
+)
+
+var progressBarColor = color.RGBA{0xeb, 0xd3, 0x20, 0xff}
+
+// drawLoadingScreen draws the message and the construction progress of a building.
+func drawLoadingScreen(screen *ebiten.Image, progress game.Progress) {
+	msg := lang.T("Currently under construction.\nPlease wait a moment.") + "\n\n"
+	msg += lang.Sprintf("Rooms: %d / %d  Branches: %d  Attempts: %d", progress.VisitedRooms, progress.RequiredRooms, progress.Branches, progress.Attempts)
+	msg += "\n\n\n" + lang.T("Esc: Title")
+	textutil.Print(screen, msg)
+
+	x, y := float32(0), float32(4*textutil.LineHeight+(textutil.LineHeight-progressBarHeight)/2)
+	vector.DrawFilledRect(screen, x, y, progressBarWidth*float32(progress.Rate()), progressBarHeight, progressBarColor, false)
+	vector.StrokeRect(screen, x, y, progressBarWidth, progressBarHeight, 1, color.White, false)
+}
@@ -4,26 +4,53 @@
 package main
 
 import (
-	"bytes"
-	_ "embed"
+	"flag"
 	"fmt"
+	"math/rand/v2"
 	"os"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/audio"
-	"github.com/hajimehoshi/ebiten/v2/audio/vorbis"
 
 	"github.com/hajimehoshi/sugoimaze/internal/game"
+	"github.com/hajimehoshi/sugoimaze/internal/lang"
+	"github.com/hajimehoshi/sugoimaze/internal/synth"
 )
 
-//go:embed game.ogg
-var gameOgg []byte
-
 type GameContext interface {
 	PlayBGM(name string) error
 	StopBGM()
-	GoToGame(difficulty game.Difficulty)
+	PauseBGM()
+	ResumeBGM()
+	SetBGMHeight(height float64, nearGoal bool) error
+	PlaySE(name string) error
+	GoToGame(difficulty game.Difficulty, seed uint64)
+	ContinueGame(saveData *SaveData)
+	GoToCoop(difficulty game.Difficulty, seed uint64)
+
+	// PrefetchNextGame starts to generate the next building of the difficulty in the background.
+	// Only one building is prefetched at a time.
+	PrefetchNextGame(difficulty game.Difficulty)
+
+	// GoToNextGame starts the prefetched building, or a new building if there is none.
+	GoToNextGame(difficulty game.Difficulty, coop bool)
+
+	GoToRace(difficulty game.Difficulty, seed uint64)
+	GoToNetRace()
+	ServerAddr() string
+	LeaderboardURL() string
+	PlayerName() string
+	Achievements() *Achievements
+	Config() *Config
+	ApplyConfig()
+	GoToLeaderboard()
 	GoToTitle()
+
+	// PushScene puts the scene over the current scenes. Only the top scene is updated, and all the scenes are drawn.
+	PushScene(scene Scene)
+
+	// PopScene removes the top scene.
+	PopScene()
 }
 
 type Scene interface {
@@ -31,86 +58,295 @@ type Scene interface {
 	Draw(screen *ebiten.Image)
 }
 
+// closingHandler is a scene that saves its progress when the window is closed.
+type closingHandler interface {
+	handleClosing() error
+}
+
 type Game struct {
-	scene            Scene
+	// scenes is the scene stack. The last one is the top.
+	scenes           []Scene
+	transition       *transition
+	next             *nextGame
 	audioContext     *audio.Context
-	bgmPlayers       map[string]*audio.Player
-	currentBGMPlayer *audio.Player
+	bgm              bgm
+	bgmPausedByFocus bool
+	sePlayers        sePlayers
+	achievements     Achievements
+	config           *Config
+
+	// windowScale and fullscreen are the applied window settings.
+	windowScale int
+	fullscreen  bool
 }
 
-func NewGame() *Game {
-	return &Game{
-		scene:        &TitleScene{},
-		audioContext: audio.NewContext(48000),
+// screenSize is the width and the height of the screen in pixels.
+const screenSize = 320
+
+func NewGame() (*Game, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, err
 	}
+	audioContext := audio.NewContext(synth.SampleRate)
+	g := &Game{
+		scenes:       []Scene{&TitleScene{}},
+		audioContext: audioContext,
+		bgm: bgm{
+			context: audioContext,
+		},
+		config: config,
+	}
+	g.ApplyConfig()
+	return g, nil
 }
 
 func (g *Game) AudioContext() *audio.Context {
 	return g.audioContext
 }
 
+// PlayBGM crossfades the current BGM to the named track.
 func (g *Game) PlayBGM(name string) error {
-	player, ok := g.bgmPlayers[name]
-	if ok {
-		player.Play()
-		return nil
-	}
-	if g.bgmPlayers == nil {
-		g.bgmPlayers = map[string]*audio.Player{}
-	}
-	if name != "game" {
-		return fmt.Errorf("sugoimaze: unknown BGM name: %s", name)
+	return g.bgm.play(name)
+}
+
+// StopBGM fades out the current BGM.
+func (g *Game) StopBGM() {
+	g.bgm.stop()
+}
+
+// PauseBGM pauses the BGM without rewinding it.
+func (g *Game) PauseBGM() {
+	g.bgm.pause()
+}
+
+// ResumeBGM resumes the BGM paused by PauseBGM.
+func (g *Game) ResumeBGM() {
+	g.bgm.resume()
+}
+
+// SetBGMHeight adds the music layers to the game track by the player's height from 0 to 1.
+func (g *Game) SetBGMHeight(height float64, nearGoal bool) error {
+	return g.bgm.setHeight(height, nearGoal)
+}
+
+// PlaySE plays the sound effect. The same sound effect can be played overlapped.
+func (g *Game) PlaySE(name string) error {
+	return g.sePlayers.play(g.audioContext, name, float64(g.config.SEVolume)/maxVolume)
+}
+
+func (g *Game) Update() error {
+	// Pause the BGM while the window is not focused.
+	if focused := ebiten.IsFocused(); focused == g.bgmPausedByFocus {
+		if focused {
+			g.bgm.resume()
+		} else {
+			g.bgm.pause()
+		}
+		g.bgmPausedByFocus = !focused
 	}
-	stream, err := vorbis.DecodeWithoutResampling(bytes.NewReader(gameOgg))
-	if err != nil {
+	if err := g.bgm.update(); err != nil {
 		return err
 	}
-	loop := audio.NewInfiniteLoop(stream, stream.Length())
-	player, err = g.audioContext.NewPlayer(loop)
-	if err != nil {
+
+	if ebiten.IsWindowBeingClosed() {
+		for _, s := range g.scenes {
+			if h, ok := s.(closingHandler); ok {
+				if err := h.handleClosing(); err != nil {
+					return err
+				}
+			}
+		}
+		return ebiten.Termination
+	}
+
+	// Input is blocked during a transition.
+	if g.transition != nil {
+		if g.transition.update() {
+			g.transition = nil
+		} else if g.transition.isHalfway() {
+			// Update the incoming scene once so that it is initialized before it is drawn.
+			if err := g.transition.to[len(g.transition.to)-1].Update(g); err != nil {
+				return err
+			}
+		}
+	} else if err := g.scenes[len(g.scenes)-1].Update(g); err != nil {
 		return err
 	}
-	g.bgmPlayers[name] = player
-	g.currentBGMPlayer = player
-	player.Play()
+	g.achievements.Update()
 	return nil
 }
 
-func (g *Game) StopBGM() {
-	if g.currentBGMPlayer != nil {
-		g.currentBGMPlayer.Pause()
-		g.currentBGMPlayer.Rewind()
+func (g *Game) Draw(screen *ebiten.Image) {
+	if g.transition != nil {
+		g.transition.draw(screen)
+	} else {
+		drawScenes(screen, g.scenes)
 	}
+	g.achievements.Draw(screen)
 }
 
-func (g *Game) Update() error {
-	if err := g.scene.Update(g); err != nil {
-		return err
+// drawScenes draws the scenes from the bottom to the top.
+func drawScenes(screen *ebiten.Image, scenes []Scene) {
+	for _, s := range scenes {
+		s.Draw(screen)
 	}
-	return nil
 }
 
-func (g *Game) Draw(screen *ebiten.Image) {
-	g.scene.Draw(screen)
+// goTo replaces all the scenes with the given scene with the transition effect.
+func (g *Game) goTo(scene Scene, kind transitionKind) {
+	scenes := []Scene{scene}
+	if g.transition != nil {
+		g.transition.to = scenes
+	} else {
+		g.transition = &transition{
+			kind: kind,
+			from: g.scenes,
+			to:   scenes,
+		}
+	}
+	g.scenes = scenes
+}
+
+func (g *Game) PushScene(scene Scene) {
+	g.scenes = append(g.scenes, scene)
+}
+
+func (g *Game) PopScene() {
+	if len(g.scenes) <= 1 {
+		return
+	}
+	g.scenes[len(g.scenes)-1] = nil
+	g.scenes = g.scenes[:len(g.scenes)-1]
 }
 
 func (g *Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
-	return outsideWidth / 2, outsideHeight / 2
+	return outsideWidth / g.config.WindowScale, outsideHeight / g.config.WindowScale
+}
+
+func (g *Game) GoToGame(level game.Difficulty, seed uint64) {
+	g.goTo(NewGameScene(level, seed), transitionElevator)
+}
+
+func (g *Game) ContinueGame(saveData *SaveData) {
+	g.goTo(NewGameSceneFromSaveData(saveData), transitionElevator)
+}
+
+func (g *Game) GoToCoop(difficulty game.Difficulty, seed uint64) {
+	g.goTo(NewCoopGameScene(difficulty, seed), transitionElevator)
+}
+
+// nextGame is a building prefetched for the next game.
+type nextGame struct {
+	difficulty game.Difficulty
+	seed       uint64
+	loader     *levelLoader
+}
+
+func (g *Game) PrefetchNextGame(difficulty game.Difficulty) {
+	if g.next != nil {
+		if g.next.difficulty == difficulty {
+			return
+		}
+		g.next.loader.cancel()
+	}
+	seed := rand.Uint64()
+	g.next = &nextGame{
+		difficulty: difficulty,
+		seed:       seed,
+		loader:     newLevelLoader(difficulty, seed),
+	}
+}
+
+func (g *Game) GoToNextGame(difficulty game.Difficulty, coop bool) {
+	g.PrefetchNextGame(difficulty)
+	next := g.next
+	g.next = nil
+	g.goTo(&GameScene{
+		difficulty: next.difficulty,
+		seed:       next.seed,
+		loader:     next.loader,
+		coop:       coop,
+	}, transitionElevator)
+}
+
+func (g *Game) GoToRace(difficulty game.Difficulty, seed uint64) {
+	g.goTo(NewRaceScene(difficulty, seed), transitionElevator)
+}
+
+func (g *Game) GoToNetRace() {
+	g.goTo(NewNetRaceScene(*flagServer, *flagName), transitionElevator)
+}
+
+// ServerAddr returns the address of the race server, or an empty string if it is not specified.
+func (g *Game) ServerAddr() string {
+	return *flagServer
+}
+
+func (g *Game) GoToLeaderboard() {
+	g.goTo(&LeaderboardScene{speed: g.config.Speed}, transitionWipe)
 }
 
-func (g *Game) GoToGame(level game.Difficulty) {
-	g.scene = NewGameScene(level)
+// LeaderboardURL returns the URL of the leaderboard service, or an empty string if it is not specified.
+func (g *Game) LeaderboardURL() string {
+	return *flagLeaderboard
+}
+
+func (g *Game) PlayerName() string {
+	return *flagName
+}
+
+func (g *Game) Achievements() *Achievements {
+	return &g.achievements
+}
+
+func (g *Game) Config() *Config {
+	return g.config
+}
+
+// ApplyConfig applies the current settings.
+func (g *Game) ApplyConfig() {
+	lang.SetCurrent(g.config.Language)
+	g.bgm.setVolume(float64(g.config.BGMVolume) / maxVolume)
+	g.bgm.crossfadeTicks = crossfadeTicks(g.config.BGMCrossfadeMillis)
+	// Change the window only when needed so that the window resized by the user is kept.
+	if g.windowScale != g.config.WindowScale {
+		ebiten.SetWindowSize(screenSize*g.config.WindowScale, screenSize*g.config.WindowScale)
+		g.windowScale = g.config.WindowScale
+	}
+	if g.fullscreen != g.config.Fullscreen {
+		ebiten.SetFullscreen(g.config.Fullscreen)
+		g.fullscreen = g.config.Fullscreen
+	}
 }
 
 func (g *Game) GoToTitle() {
-	g.scene = &TitleScene{}
+	// Discard the prefetched building.
+	if g.next != nil {
+		g.next.loader.cancel()
+		g.next = nil
+	}
+	g.goTo(&TitleScene{}, transitionFade)
 }
 
+var (
+	flagServer      = flag.String("server", "", "address of the race server to join (e.g. 192.168.0.2:7777)")
+	flagName        = flag.String("name", "Gopher", "player name for races over a network and the leaderboard")
+	flagLeaderboard = flag.String("leaderboard", "", "URL of the leaderboard service to submit runs to (e.g. http://localhost:8080)")
+)
+
 func main() {
+	flag.Parse()
+
 	ebiten.SetWindowTitle("The Sugoi Maze Building")
-	ebiten.SetWindowSize(640, 640)
 	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
-	if err := ebiten.RunGame(NewGame()); err != nil {
+	ebiten.SetWindowClosingHandled(true)
+	g, err := NewGame()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	if err := ebiten.RunGame(g); err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
 		os.Exit(1)
 	}
@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+type menuItem struct {
+	label  string
+	action func(game GameContext)
+
+	// adjust is called with -1 or 1 when the left or right key is pressed, if adjust is not nil.
+	adjust func(delta int)
+
+	// help is the text shown when the item is selected.
+	help string
+}
+
+// menu is a list of items selected with a cursor.
+type menu struct {
+	cursorIndex int
+}
+
+func (m *menu) update(game GameContext, items []menuItem) {
+	if inpututil.IsKeyJustPressed(ebiten.KeyDown) || inpututil.IsKeyJustPressed(ebiten.KeyS) {
+		m.cursorIndex++
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyUp) || inpututil.IsKeyJustPressed(ebiten.KeyW) {
+		m.cursorIndex--
+	}
+	m.cursorIndex = min(max(m.cursorIndex, 0), len(items)-1)
+
+	item := items[m.cursorIndex]
+	if item.adjust != nil {
+		if inpututil.IsKeyJustPressed(ebiten.KeyLeft) || inpututil.IsKeyJustPressed(ebiten.KeyA) {
+			item.adjust(-1)
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyRight) || inpututil.IsKeyJustPressed(ebiten.KeyD) {
+			item.adjust(1)
+		}
+	}
+	if item.action != nil && (inpututil.IsKeyJustPressed(ebiten.KeySpace) || inpututil.IsKeyJustPressed(ebiten.KeyEnter)) {
+		item.action(game)
+	}
+}
+
+// text returns the items with the cursor, one item per line.
+func (m *menu) text(items []menuItem) string {
+	var str string
+	for i, item := range items {
+		if i == m.cursorIndex {
+			str += " -> "
+		} else {
+			str += "    "
+		}
+		str += item.label + "\n"
+	}
+	return str
+}
+
+// help returns the help text of the selected item.
+func (m *menu) help(items []menuItem) string {
+	if m.cursorIndex < 0 || m.cursorIndex >= len(items) {
+		return ""
+	}
+	return items[m.cursorIndex].help
+}
@@ -0,0 +1,42 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package main
+
+import (
+	"github.com/hajimehoshi/sugoimaze/internal/game"
+	"github.com/hajimehoshi/sugoimaze/internal/synth"
+)
+
+const musicTempo = 120
+
+var (
+	// pulseLayer is a bass line that gets louder as the player climbs.
+	pulseLayer = synth.Sequence{
+		Notes:        []int{45, 45, 57, 45, 43, 43, 55, 43, 41, 41, 53, 41, 43, 43, 55, 47},
+		Tempo:        musicTempo,
+		StepsPerBeat: 2,
+		Waveform:     synth.Square,
+		Volume:       0.12,
+		Envelope:     synth.Envelope{Decay: 0.25},
+	}
+
+	// goalLayer is an arpeggio that is added near the goal.
+	goalLayer = synth.Sequence{
+		Notes:        []int{69, 72, 76, 81, 67, 71, 74, 79, 65, 69, 72, 77, 67, 71, 74, 79},
+		Tempo:        musicTempo,
+		StepsPerBeat: 4,
+		Waveform:     synth.Triangle,
+		Volume:       0.1,
+		Envelope:     synth.Envelope{Decay: 0.125},
+	}
+)
+
+// musicHeight returns the intensity of the adaptive music from the player's floor, and whether the player is near the goal.
+func musicHeight(field *game.Field) (height float64, nearGoal bool) {
+	floor, count := field.Floor()
+	if count <= 1 {
+		return 1, true
+	}
+	return float64(floor-1) / float64(count-1), floor >= count-1
+}
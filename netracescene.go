@@ -0,0 +1,238 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package main
+
+import (
+	"image/color"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"github.com/hajimehoshi/sugoimaze/internal/game"
+	"github.com/hajimehoshi/sugoimaze/internal/lang"
+	"github.com/hajimehoshi/sugoimaze/internal/netrace"
+	"github.com/hajimehoshi/sugoimaze/internal/textutil"
+)
+
+type dialResult struct {
+	client *netrace.Client
+	err    error
+}
+
+// NetRaceScene is a scene where players race over a network.
+type NetRaceScene struct {
+	addr string
+	name string
+
+	dialCh chan dialResult
+	client *netrace.Client
+	loader *levelLoader
+	field  *game.Field
+
+	bgmStarted bool
+	players    []string
+	required   int
+	id         int
+	rivals     map[int]game.Rival
+	lastState  netrace.PlayerState
+	goalSent   bool
+	winner     string
+	errMsg     string
+}
+
+func NewNetRaceScene(addr string, name string) *NetRaceScene {
+	return &NetRaceScene{
+		addr:   addr,
+		name:   name,
+		rivals: map[int]game.Rival{},
+	}
+}
+
+func (n *NetRaceScene) Update(gameContext GameContext) error {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) || (n.isOver() && (inpututil.IsKeyJustPressed(ebiten.KeySpace) || inpututil.IsKeyJustPressed(ebiten.KeyEnter))) {
+		if n.client != nil {
+			_ = n.client.Close()
+		}
+		if n.loader != nil {
+			n.loader.cancel()
+		}
+		gameContext.GoToTitle()
+		return nil
+	}
+	if n.isOver() {
+		return nil
+	}
+
+	if n.client == nil && n.dialCh == nil {
+		n.dialCh = make(chan dialResult, 1)
+		go func() {
+			c, err := netrace.Dial(n.addr, n.name)
+			n.dialCh <- dialResult{client: c, err: err}
+		}()
+	}
+	if n.client == nil {
+		select {
+		case r := <-n.dialCh:
+			if r.err != nil {
+				n.errMsg = r.err.Error()
+				return nil
+			}
+			n.client = r.client
+		default:
+			return nil
+		}
+	}
+
+	if err := n.receive(gameContext); err != nil {
+		return err
+	}
+
+	if n.field == nil {
+		if n.loader == nil {
+			return nil
+		}
+		d := n.loader.fieldData()
+		if d == nil {
+			return nil
+		}
+		n.loader = nil
+		n.field = game.NewFieldWithData(d)
+	}
+	n.field.SetPalette(gameContext.Config().Palette)
+
+	if !n.bgmStarted {
+		if err := gameContext.PlayBGM(bgmNameForDifficulty(n.field.Difficulty())); err != nil {
+			return err
+		}
+		n.bgmStarted = true
+	}
+
+	rivals := make([]game.Rival, 0, len(n.rivals))
+	for _, r := range n.rivals {
+		rivals = append(rivals, r)
+	}
+	n.field.SetRivals(rivals)
+	n.field.Update(playerInput(gameContext.Config()))
+	if err := playFieldSEs(gameContext, n.field); err != nil {
+		return err
+	}
+	if err := gameContext.SetBGMHeight(musicHeight(n.field)); err != nil {
+		return err
+	}
+
+	x, y := n.field.PlayerPosition()
+	d0, d1 := n.field.DepthState()
+	state := netrace.PlayerState{X: x, Y: y, Depth0: d0, Depth1: d1}
+	if state != n.lastState {
+		if err := n.client.SendState(state); err != nil {
+			n.errMsg = err.Error()
+			return nil
+		}
+		n.lastState = state
+	}
+	if n.field.IsGoalReached() && !n.goalSent {
+		if err := n.client.SendGoal(); err != nil {
+			n.errMsg = err.Error()
+			return nil
+		}
+		n.goalSent = true
+	}
+	return nil
+}
+
+func (n *NetRaceScene) receive(gameContext GameContext) error {
+	for {
+		select {
+		case msg, ok := <-n.client.Messages():
+			if !ok {
+				if n.winner == "" {
+					n.errMsg = lang.T("The connection was closed.")
+					if err := n.client.Err(); err != nil {
+						n.errMsg = err.Error()
+					}
+				}
+				return nil
+			}
+			if err := n.handleMessage(gameContext, msg); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+}
+
+func (n *NetRaceScene) handleMessage(gameContext GameContext, msg *netrace.Message) error {
+	switch msg.Type {
+	case netrace.TypeLobby:
+		n.players = msg.Players
+		n.required = msg.Required
+	case netrace.TypeStart:
+		n.players = msg.Players
+		n.id = msg.ID
+		n.loader = newLevelLoader(game.Difficulty(msg.Difficulty), msg.Seed)
+	case netrace.TypeState:
+		if msg.State == nil || msg.ID == n.id {
+			return nil
+		}
+		n.rivals[msg.ID] = game.Rival{
+			X:      msg.State.X,
+			Y:      msg.State.Y,
+			Depth0: msg.State.Depth0,
+			Depth1: msg.State.Depth1,
+		}
+	case netrace.TypeWinner:
+		n.winner = msg.Name
+		if msg.ID == n.id {
+			n.winner += lang.T(" (You)")
+			return gameContext.PlayBGM("goal")
+		}
+		// The goal jingle is only for the winner.
+		gameContext.StopBGM()
+	}
+	return nil
+}
+
+func (n *NetRaceScene) isOver() bool {
+	return n.winner != "" || n.errMsg != ""
+}
+
+func (n *NetRaceScene) Draw(screen *ebiten.Image) {
+	screen.Fill(color.RGBA{0, 0, 0, 255})
+
+	if n.field == nil {
+		var msg string
+		switch {
+		case n.errMsg != "":
+			msg = lang.Sprintf("Error: %s", n.errMsg) + "\n\n" + lang.T("Space, Enter: Title")
+		case n.client == nil:
+			msg = lang.Sprintf("Connecting to %s...", n.addr)
+		case n.loader != nil:
+			drawLoadingScreen(screen, n.loader.progress())
+			return
+		default:
+			msg = lang.Sprintf("Waiting for players (%d/%d)", len(n.players), n.required) + "\n\n"
+			msg += strings.Join(n.players, "\n")
+			msg += "\n\n" + lang.T("Esc: Title")
+		}
+		textutil.Print(screen, msg)
+		return
+	}
+
+	n.field.Draw(screen)
+
+	var msg string
+	switch {
+	case n.winner != "":
+		msg = lang.Sprintf("WINNER: %s", n.winner) + "\n" + lang.T("Space, Enter: Title")
+	case n.errMsg != "":
+		msg = lang.Sprintf("Error: %s", n.errMsg) + "\n" + lang.T("Space, Enter: Title")
+	case n.field.IsGoalReached():
+		msg = lang.T("GOAL!")
+	}
+	if msg != "" {
+		printBottom(screen, msg)
+	}
+}
@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"github.com/hajimehoshi/sugoimaze/internal/lang"
+	"github.com/hajimehoshi/sugoimaze/internal/textutil"
+)
+
+var overlayBackgroundColor = color.RGBA{0, 0, 0, 0xe0}
+
+// drawOverlayBackground darkens the scenes under an overlay scene.
+func drawOverlayBackground(screen *ebiten.Image) {
+	b := screen.Bounds()
+	vector.DrawFilledRect(screen, float32(b.Min.X), float32(b.Min.Y), float32(b.Dx()), float32(b.Dy()), overlayBackgroundColor, false)
+}
+
+// PauseScene is an overlay scene to pause the game. The game under the overlay is not updated.
+type PauseScene struct {
+	menu menu
+}
+
+func (p *PauseScene) menuItems() []menuItem {
+	return []menuItem{
+		{
+			label: lang.T("Resume"),
+			action: func(gameContext GameContext) {
+				gameContext.PopScene()
+			},
+		},
+		{
+			label: lang.T("Settings"),
+			action: func(gameContext GameContext) {
+				gameContext.PushScene(&SettingsScene{inRun: true})
+			},
+		},
+		{
+			label: lang.T("Title"),
+			action: func(gameContext GameContext) {
+				gameContext.GoToTitle()
+			},
+			help: lang.T("The building in progress can be continued\nfrom the title, where you are now."),
+		},
+	}
+}
+
+func (p *PauseScene) Update(gameContext GameContext) error {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		gameContext.PopScene()
+		return nil
+	}
+	p.menu.update(gameContext, p.menuItems())
+	return nil
+}
+
+func (p *PauseScene) Draw(screen *ebiten.Image) {
+	drawOverlayBackground(screen)
+	items := p.menuItems()
+	msg := lang.T("Paused") + "\n\n"
+	msg += p.menu.text(items)
+	help := p.menu.help(items)
+	if help == "" {
+		help = lang.T("Space, Enter: Select")
+	}
+	msg += "\n" + help + "\n" + lang.T("Esc: Resume")
+	textutil.Print(screen, msg)
+}
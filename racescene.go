@@ -0,0 +1,142 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package main
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"github.com/hajimehoshi/sugoimaze/internal/game"
+	"github.com/hajimehoshi/sugoimaze/internal/lang"
+	"github.com/hajimehoshi/sugoimaze/internal/textutil"
+)
+
+// RaceScene is a scene where two players race in the same building side by side.
+type RaceScene struct {
+	bgmStarted bool
+	difficulty game.Difficulty
+	seed       uint64
+	fields     [2]*game.Field
+	loader     *levelLoader
+
+	// winner is the index of the player who reached the goal first.
+	// winner is -1 while racing, and 2 when both players reached the goal at the same time.
+	winner int
+}
+
+var raceControls = [2]game.Controls{game.PlayerOneControls, game.PlayerTwoControls}
+
+func NewRaceScene(difficulty game.Difficulty, seed uint64) *RaceScene {
+	return &RaceScene{
+		difficulty: difficulty,
+		seed:       seed,
+		winner:     -1,
+	}
+}
+
+func (r *RaceScene) Update(gameContext GameContext) error {
+	if !r.bgmStarted && r.fields[0] != nil {
+		if err := gameContext.PlayBGM(bgmNameForDifficulty(r.difficulty)); err != nil {
+			return err
+		}
+		r.bgmStarted = true
+	}
+
+	if r.fields[0] == nil {
+		if r.loader == nil {
+			r.loader = newLevelLoader(r.difficulty, r.seed)
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+			r.loader.cancel()
+			gameContext.GoToTitle()
+			return nil
+		}
+		d := r.loader.fieldData()
+		if d == nil {
+			return nil
+		}
+		r.loader = nil
+		r.fields[0] = game.NewFieldWithData(d)
+		r.fields[1] = game.NewFieldWithData(d)
+		for _, f := range r.fields {
+			f.SetSpeed(gameContext.Config().Speed)
+		}
+	}
+	// The fields share the same FieldData.
+	r.fields[0].SetPalette(gameContext.Config().Palette)
+
+	if r.winner >= 0 {
+		if inpututil.IsKeyJustPressed(ebiten.KeySpace) || inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+			gameContext.GoToTitle()
+		}
+		return nil
+	}
+
+	var height float64
+	var nearGoal bool
+	for i, f := range r.fields {
+		f.Update(raceControls[i])
+		if err := playFieldSEs(gameContext, f); err != nil {
+			return err
+		}
+		// The music follows the leading player.
+		h, g := musicHeight(f)
+		height = max(height, h)
+		nearGoal = nearGoal || g
+	}
+	if err := gameContext.SetBGMHeight(height, nearGoal); err != nil {
+		return err
+	}
+	switch {
+	case r.fields[0].IsGoalReached() && r.fields[1].IsGoalReached():
+		r.winner = 2
+	case r.fields[0].IsGoalReached():
+		r.winner = 0
+	case r.fields[1].IsGoalReached():
+		r.winner = 1
+	}
+	if r.winner >= 0 {
+		if err := gameContext.PlayBGM("goal"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *RaceScene) Draw(screen *ebiten.Image) {
+	screen.Fill(color.RGBA{0, 0, 0, 255})
+
+	if r.fields[0] == nil {
+		var progress game.Progress
+		if r.loader != nil {
+			progress = r.loader.progress()
+		}
+		drawLoadingScreen(screen, progress)
+		return
+	}
+
+	w, h := screen.Bounds().Dx(), screen.Bounds().Dy()
+	r.fields[0].Draw(screen.SubImage(image.Rect(0, 0, w/2, h)).(*ebiten.Image))
+	r.fields[1].Draw(screen.SubImage(image.Rect(w/2, 0, w, h)).(*ebiten.Image))
+	vector.StrokeLine(screen, float32(w/2), 0, float32(w/2), float32(h), 1, color.White, false)
+
+	var msg string
+	switch r.winner {
+	case -1:
+		textutil.PrintAt(screen, lang.T("1P: WASD, Space"), 0, h-textutil.LineHeight)
+		textutil.PrintAt(screen, lang.T("2P: Arrows, Enter"), w/2, h-textutil.LineHeight)
+		return
+	case 0:
+		msg = lang.T("1P WINS!")
+	case 1:
+		msg = lang.T("2P WINS!")
+	case 2:
+		msg = lang.T("DRAW!")
+	}
+	printBottom(screen, msg+"\n"+lang.T("Space, Enter: Title"))
+}
@@ -0,0 +1,218 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/sugoimaze/internal/game"
+)
+
+// Record is a record of a completed run.
+type Record struct {
+	Seed uint64 `json:"seed"`
+
+	// Speed is the player's movement speed. Records are compared only with ones at the same speed.
+	// Records before the speed setting don't have this field, and they are at the normal speed.
+	Speed game.Speed `json:"speed,omitempty"`
+
+	Ticks         int       `json:"ticks"`
+	Steps         int       `json:"steps"`
+	SwitchPresses int       `json:"switchPresses"`
+	Date          time.Time `json:"date"`
+}
+
+func recordsDir() (string, error) {
+	dir, err := storageDir()
+	if err != nil {
+		return "", err
+	}
+	if dir == "" {
+		return "", nil
+	}
+	return filepath.Join(dir, "records"), nil
+}
+
+// recordsPath returns the file of the records of the building.
+func recordsPath(difficulty game.Difficulty, seed uint64) (string, error) {
+	dir, err := recordsDir()
+	if err != nil {
+		return "", err
+	}
+	if dir == "" {
+		return "", nil
+	}
+	return filepath.Join(dir, fmt.Sprintf("%s-%d.json", strings.ToLower(difficulty.String()), seed)), nil
+}
+
+// LoadRecords loads the records of the building at the speed, sorted from the fastest.
+func LoadRecords(difficulty game.Difficulty, seed uint64, speed game.Speed) ([]Record, error) {
+	if err := migrateRecords(difficulty); err != nil {
+		return nil, err
+	}
+	path, err := recordsPath(difficulty, seed)
+	if err != nil {
+		return nil, err
+	}
+	records, err := readRecords(path)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(records, func(r Record) bool {
+		return r.Speed != speed
+	}), nil
+}
+
+// RecordSeeds returns the seeds of the buildings of the difficulty that have records, from the most recently cleared one.
+func RecordSeeds(difficulty game.Difficulty) ([]uint64, error) {
+	if err := migrateRecords(difficulty); err != nil {
+		return nil, err
+	}
+	dir, err := recordsDir()
+	if err != nil {
+		return nil, err
+	}
+	if dir == "" {
+		return nil, nil
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, strings.ToLower(difficulty.String())+"-*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var seeds []uint64
+	dates := map[uint64]time.Time{}
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".json")
+		seed, err := strconv.ParseUint(name[strings.LastIndex(name, "-")+1:], 10, 64)
+		if err != nil {
+			continue
+		}
+		records, err := readRecords(path)
+		if err != nil {
+			return nil, err
+		}
+		if len(records) == 0 {
+			continue
+		}
+		seeds = append(seeds, seed)
+		for _, r := range records {
+			if r.Date.After(dates[seed]) {
+				dates[seed] = r.Date
+			}
+		}
+	}
+	slices.SortFunc(seeds, func(a, b uint64) int {
+		return dates[b].Compare(dates[a])
+	})
+	return seeds, nil
+}
+
+// AddRecord adds a record of the building.
+func AddRecord(difficulty game.Difficulty, record Record) error {
+	if err := migrateRecords(difficulty); err != nil {
+		return err
+	}
+	path, err := recordsPath(difficulty, record.Seed)
+	if err != nil {
+		return err
+	}
+	if path == "" {
+		return nil
+	}
+	records, err := readRecords(path)
+	if err != nil {
+		return err
+	}
+	return writeRecords(path, append(records, record))
+}
+
+// migrateRecords splits the records of the difficulty in one file, which were kept before the records per building, into the files per building.
+func migrateRecords(difficulty game.Difficulty) error {
+	dir, err := recordsDir()
+	if err != nil {
+		return err
+	}
+	if dir == "" {
+		return nil
+	}
+	oldPath := filepath.Join(dir, strings.ToLower(difficulty.String())+".json")
+	oldRecords, err := readRecords(oldPath)
+	if err != nil {
+		return err
+	}
+	if oldRecords == nil {
+		return nil
+	}
+
+	recordsBySeed := map[uint64][]Record{}
+	for _, r := range oldRecords {
+		recordsBySeed[r.Seed] = append(recordsBySeed[r.Seed], r)
+	}
+	for seed, rs := range recordsBySeed {
+		path, err := recordsPath(difficulty, seed)
+		if err != nil {
+			return err
+		}
+		records, err := readRecords(path)
+		if err != nil {
+			return err
+		}
+		if err := writeRecords(path, append(records, rs...)); err != nil {
+			return err
+		}
+	}
+	return os.Remove(oldPath)
+}
+
+// readRecords reads the records in the file, sorted from the fastest.
+// readRecords returns nil if there is no file.
+func readRecords(path string) ([]Record, error) {
+	if path == "" {
+		return nil, nil
+	}
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var records []Record
+	if err := json.Unmarshal(bs, &records); err != nil {
+		return nil, err
+	}
+	sortRecords(records)
+	return records, nil
+}
+
+func writeRecords(path string, records []Record) error {
+	sortRecords(records)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	bs, err := json.MarshalIndent(records, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, bs, 0644)
+}
+
+func sortRecords(records []Record) {
+	slices.SortStableFunc(records, func(a, b Record) int {
+		if a.Ticks != b.Ticks {
+			return a.Ticks - b.Ticks
+		}
+		return a.Steps - b.Steps
+	})
+}
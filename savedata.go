@@ -0,0 +1,183 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/hajimehoshi/sugoimaze/internal/game"
+)
+
+// SaveData is the building in progress.
+type SaveData struct {
+	Difficulty game.Difficulty `json:"difficulty"`
+	Seed       uint64          `json:"seed"`
+	Markers    []game.Marker   `json:"markers,omitempty"`
+	Coop       bool            `json:"coop,omitempty"`
+
+	// Speed, Replay and Toggles are the run so far. The run is continued by replaying them.
+	Speed   game.Speed        `json:"speed,omitempty"`
+	Replay  []game.InputState `json:"replay,omitempty"`
+	Toggles []Toggle          `json:"toggles,omitempty"`
+}
+
+// Toggle is a toggle of the switches or the doors by the second player in the co-op mode.
+type Toggle struct {
+	// Tick is the number of the run's ticks when the toggle happened.
+	Tick  int  `json:"tick"`
+	Doors bool `json:"doors,omitempty"`
+}
+
+// storageDir returns the directory to store files.
+// storageDir returns an empty string if there is no storage, e.g., on browsers.
+func storageDir() (string, error) {
+	if runtime.GOOS == "js" {
+		return "", nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "sugoimaze"), nil
+}
+
+func saveDataPath() (string, error) {
+	dir, err := storageDir()
+	if err != nil {
+		return "", err
+	}
+	if dir == "" {
+		return "", nil
+	}
+	return filepath.Join(dir, "save.json"), nil
+}
+
+// LoadSaveData loads the save file.
+// LoadSaveData returns nil if there is no save file.
+func LoadSaveData() (*SaveData, error) {
+	path, err := saveDataPath()
+	if err != nil {
+		return nil, err
+	}
+	if path == "" {
+		return nil, nil
+	}
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var s SaveData
+	if err := json.Unmarshal(bs, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (s *SaveData) Save() error {
+	path, err := saveDataPath()
+	if err != nil {
+		return err
+	}
+	if path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	bs, err := json.MarshalIndent(s, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, bs, 0644)
+}
+
+func RemoveSaveData() error {
+	path, err := saveDataPath()
+	if err != nil {
+		return err
+	}
+	if path == "" {
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func bestRecordingPath(difficulty game.Difficulty, seed uint64, speed game.Speed) (string, error) {
+	dir, err := storageDir()
+	if err != nil {
+		return "", err
+	}
+	if dir == "" {
+		return "", nil
+	}
+	name := fmt.Sprintf("%s-%d", strings.ToLower(difficulty.String()), seed)
+	// The ghosts at the normal speed keep the names from before the speed setting.
+	if speed != game.SpeedNormal {
+		name += "-" + strings.ToLower(speed.String())
+	}
+	return filepath.Join(dir, "ghosts", name+".json"), nil
+}
+
+// LoadBestRecording loads the personal best run of the building at the speed.
+// LoadBestRecording returns nil if there is no record.
+func LoadBestRecording(difficulty game.Difficulty, seed uint64, speed game.Speed) (*game.Recording, error) {
+	path, err := bestRecordingPath(difficulty, seed, speed)
+	if err != nil {
+		return nil, err
+	}
+	if path == "" {
+		return nil, nil
+	}
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var r game.Recording
+	if err := json.Unmarshal(bs, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// SaveBestRecording saves the run if it is better than the personal best of the building at the speed.
+func SaveBestRecording(difficulty game.Difficulty, seed uint64, speed game.Speed, recording *game.Recording) error {
+	best, err := LoadBestRecording(difficulty, seed, speed)
+	if err != nil {
+		return err
+	}
+	if best != nil && best.Ticks() <= recording.Ticks() {
+		return nil
+	}
+	path, err := bestRecordingPath(difficulty, seed, speed)
+	if err != nil {
+		return err
+	}
+	if path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	bs, err := json.Marshal(recording)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, bs, 0644)
+}
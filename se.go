@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+
+	"github.com/hajimehoshi/sugoimaze/internal/game"
+	"github.com/hajimehoshi/sugoimaze/internal/synth"
+)
+
+// maxSEPlayers is the maximum number of players for each sound effect to play it overlapped.
+const maxSEPlayers = 4
+
+// sePlayers is a pool of audio players for sound effects.
+type sePlayers struct {
+	pcms    map[string][]byte
+	players map[string][]*audio.Player
+}
+
+// pcm returns the PCM of the sound effect synthesized from the preset.
+func (s *sePlayers) pcm(name string) ([]byte, error) {
+	if pcm, ok := s.pcms[name]; ok {
+		return pcm, nil
+	}
+	sound, ok := synth.Presets[name]
+	if !ok {
+		return nil, fmt.Errorf("sugoimaze: unknown SE name: %s", name)
+	}
+	pcm := sound.PCM()
+	if s.pcms == nil {
+		s.pcms = map[string][]byte{}
+	}
+	s.pcms[name] = pcm
+	return pcm, nil
+}
+
+// play plays the sound effect with a player that is not playing.
+// If all the players are playing, the one that has played the longest is rewound.
+func (s *sePlayers) play(context *audio.Context, name string, volume float64) error {
+	var player *audio.Player
+	for _, p := range s.players[name] {
+		if !p.IsPlaying() {
+			player = p
+			break
+		}
+	}
+	if player == nil && len(s.players[name]) < maxSEPlayers {
+		pcm, err := s.pcm(name)
+		if err != nil {
+			return err
+		}
+		player = context.NewPlayerFromBytes(pcm)
+		if s.players == nil {
+			s.players = map[string][]*audio.Player{}
+		}
+		s.players[name] = append(s.players[name], player)
+	}
+	if player == nil {
+		for _, p := range s.players[name] {
+			if player == nil || p.Position() > player.Position() {
+				player = p
+			}
+		}
+	}
+
+	player.SetVolume(volume)
+	if err := player.Rewind(); err != nil {
+		return err
+	}
+	player.Play()
+	return nil
+}
+
+// fieldEventSEs are the sound effects for the field events.
+var fieldEventSEs = map[game.Event]string{
+	game.EventStep:    "footstep",
+	game.EventLadder:  "ladder",
+	game.EventSwitch:  "switch",
+	game.EventDoor:    "door",
+	game.EventBlocked: "blocked",
+	game.EventGoal:    "goal",
+}
+
+// playFieldSEs plays the sound effects for the field's events.
+func playFieldSEs(gameContext GameContext, field *game.Field) error {
+	events := field.Events()
+	var ladder bool
+	for _, e := range events {
+		if e == game.EventLadder {
+			ladder = true
+		}
+	}
+	for _, e := range events {
+		// A ladder sound is played instead of a footstep.
+		if e == game.EventStep && ladder {
+			continue
+		}
+		name, ok := fieldEventSEs[e]
+		if !ok {
+			continue
+		}
+		if err := gameContext.PlaySE(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
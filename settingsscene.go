@@ -0,0 +1,198 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package main
+
+import (
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"github.com/hajimehoshi/sugoimaze/internal/game"
+	"github.com/hajimehoshi/sugoimaze/internal/lang"
+	"github.com/hajimehoshi/sugoimaze/internal/textutil"
+)
+
+// SettingsScene is an overlay scene to change the settings.
+// The changes are applied immediately, and saved when leaving the scene.
+type SettingsScene struct {
+	config *Config
+	menu   menu
+
+	// waitingKey is the key assignment to change with the next pressed key.
+	waitingKey *[]ebiten.Key
+
+	// usedKey is the last pressed key that was refused as it is assigned to another action.
+	usedKey    ebiten.Key
+	hasUsedKey bool
+
+	// inRun hides the settings that cannot be changed during a run, e.g., when opened from the pause menu.
+	inRun bool
+
+	leaving bool
+}
+
+func (s *SettingsScene) menuItems() []menuItem {
+	c := s.config
+	items := []menuItem{
+		{
+			label: lang.Sprintf("BGM Volume: < %d >", c.BGMVolume),
+			adjust: func(delta int) {
+				c.BGMVolume = min(max(c.BGMVolume+delta, 0), maxVolume)
+			},
+		},
+		{
+			label: lang.Sprintf("SE Volume: < %d >", c.SEVolume),
+			adjust: func(delta int) {
+				c.SEVolume = min(max(c.SEVolume+delta, 0), maxVolume)
+			},
+		},
+		{
+			label: lang.Sprintf("Window Scale: < %dx >", c.WindowScale),
+			adjust: func(delta int) {
+				c.WindowScale = min(max(c.WindowScale+delta, 1), maxWindowScale)
+			},
+		},
+		{
+			label: lang.Sprintf("Fullscreen: < %s >", onOff(c.Fullscreen)),
+			action: func(gameContext GameContext) {
+				c.Fullscreen = !c.Fullscreen
+			},
+			adjust: func(delta int) {
+				c.Fullscreen = !c.Fullscreen
+			},
+		},
+		{
+			label: lang.Sprintf("Language: < %s >", c.Language),
+			adjust: func(delta int) {
+				c.Language = c.Language.Next(delta)
+			},
+		},
+		{
+			label: lang.Sprintf("Palette: < %s >", lang.T(c.Palette.String())),
+			adjust: func(delta int) {
+				c.Palette = c.Palette.Next(delta)
+			},
+		},
+	}
+	// The speed is fixed during a run so that the replay can be simulated at one speed.
+	if !s.inRun {
+		items = append(items, menuItem{
+			label: lang.Sprintf("Speed: < %s >", lang.T(c.Speed.String())),
+			adjust: func(delta int) {
+				c.Speed = min(max(c.Speed+game.Speed(delta), game.SpeedSlow), game.SpeedFast)
+			},
+			help: lang.T("The speed applies from the next building."),
+		})
+	}
+
+	for _, k := range []struct {
+		format string
+		keys   *[]ebiten.Key
+	}{
+		{"Up: %s", &c.Controls.Up},
+		{"Down: %s", &c.Controls.Down},
+		{"Left: %s", &c.Controls.Left},
+		{"Right: %s", &c.Controls.Right},
+		{"Interact: %s", &c.Controls.Interact},
+	} {
+		label := lang.Sprintf(k.format, keyNames(*k.keys))
+		if s.waitingKey == k.keys {
+			label = lang.Sprintf(k.format, lang.T("Press a key (Esc: Cancel)"))
+			if s.hasUsedKey {
+				label = lang.Sprintf(k.format, lang.Sprintf("%s is already used (Esc: Cancel)", s.usedKey))
+			}
+		}
+		items = append(items, menuItem{
+			label: label,
+			action: func(gameContext GameContext) {
+				s.waitingKey = k.keys
+			},
+			help: lang.T("Space, Enter: Change the key"),
+		})
+	}
+
+	items = append(items, menuItem{
+		label: lang.T("Reset Controls"),
+		action: func(gameContext GameContext) {
+			c.Controls = game.DefaultControls
+		},
+	})
+	items = append(items, menuItem{
+		label: lang.T("Back"),
+		action: func(gameContext GameContext) {
+			s.leaving = true
+		},
+	})
+	return items
+}
+
+func onOff(on bool) string {
+	if on {
+		return lang.T("On")
+	}
+	return lang.T("Off")
+}
+
+func keyNames(keys []ebiten.Key) string {
+	names := make([]string, 0, len(keys))
+	for _, k := range keys {
+		names = append(names, k.String())
+	}
+	return strings.Join(names, ", ")
+}
+
+func (s *SettingsScene) Update(gameContext GameContext) error {
+	if s.config == nil {
+		s.config = gameContext.Config()
+	}
+
+	if s.waitingKey != nil {
+		keys := inpututil.AppendJustPressedKeys(nil)
+		if len(keys) == 0 {
+			return nil
+		}
+		if keys[0] != ebiten.KeyEscape && !s.config.Controls.Bind(s.waitingKey, keys[0]) {
+			// Wait for another key so that a key is not assigned to two actions.
+			s.usedKey = keys[0]
+			s.hasUsedKey = true
+			return nil
+		}
+		s.waitingKey = nil
+		s.hasUsedKey = false
+		return nil
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		s.leaving = true
+	} else {
+		s.menu.update(gameContext, s.menuItems())
+	}
+	gameContext.ApplyConfig()
+
+	if s.leaving {
+		if err := s.config.Save(); err != nil {
+			return err
+		}
+		gameContext.PopScene()
+	}
+	return nil
+}
+
+func (s *SettingsScene) Draw(screen *ebiten.Image) {
+	drawOverlayBackground(screen)
+	if s.config == nil {
+		return
+	}
+
+	items := s.menuItems()
+	msg := lang.T("Settings") + "\n\n"
+	msg += s.menu.text(items)
+	help := s.menu.help(items)
+	if help == "" {
+		help = lang.T("Left, Right: Change  Space, Enter: Select")
+	}
+	msg += "\n" + help + "\n" + lang.T("Esc: Back")
+	textutil.Print(screen, msg)
+}
@@ -4,56 +4,123 @@
 package main
 
 import (
+	"math/rand/v2"
+
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 
 	gamepkg "github.com/hajimehoshi/sugoimaze/internal/game"
+	"github.com/hajimehoshi/sugoimaze/internal/lang"
+	"github.com/hajimehoshi/sugoimaze/internal/textutil"
 )
 
 type TitleScene struct {
-	inited      bool
-	cursorIndex int
+	inited         bool
+	menu           menu
+	saveData       *SaveData
+	raceDifficulty gamepkg.Difficulty
+	coopDifficulty gamepkg.Difficulty
+	serverAddr     string
+	attract        attractDemo
 }
 
-func (t *TitleScene) Update(game GameContext) error {
-	if !t.inited {
-		game.StopBGM()
-		t.inited = true
-	}
-	if inpututil.IsKeyJustPressed(ebiten.KeyDown) || inpututil.IsKeyJustPressed(ebiten.KeyS) {
-		t.cursorIndex++
-	}
-	if inpututil.IsKeyJustPressed(ebiten.KeyUp) || inpututil.IsKeyJustPressed(ebiten.KeyW) {
-		t.cursorIndex--
+const singlePlayerHelp = `Arrows, WASD: Move  Space, Enter: Switches, etc.
+M, Tab: Minimap  C: Scout  N: Marker and note
+Backspace: Remove a marker  Esc: Pause`
+
+func (t *TitleScene) menuItems() []menuItem {
+	var items []menuItem
+	if t.saveData != nil {
+		label := lang.Sprintf("Continue (%s)", lang.T(t.saveData.Difficulty.String()))
+		if t.saveData.Coop {
+			label = lang.Sprintf("Continue (Co-op, %s)", lang.T(t.saveData.Difficulty.String()))
+		}
+		items = append(items, menuItem{
+			label: label,
+			action: func(game GameContext) {
+				game.ContinueGame(t.saveData)
+			},
+		})
 	}
-	if t.cursorIndex < 0 {
-		t.cursorIndex = 0
+	for _, difficulty := range []gamepkg.Difficulty{gamepkg.LevelTutorial, gamepkg.LevelEasy, gamepkg.LevelNormal, gamepkg.LevelHard, gamepkg.LevelSugoi} {
+		items = append(items, menuItem{
+			label: lang.T(difficulty.String()),
+			action: func(game GameContext) {
+				game.GoToGame(difficulty, rand.Uint64())
+			},
+		})
 	}
-	if t.cursorIndex > int(gamepkg.LevelSugoi) {
-		t.cursorIndex = int(gamepkg.LevelSugoi)
+	items = append(items, menuItem{
+		label: lang.Sprintf("2P Race: < %s >", lang.T(t.raceDifficulty.String())),
+		action: func(game GameContext) {
+			game.GoToRace(t.raceDifficulty, rand.Uint64())
+		},
+		adjust: func(delta int) {
+			t.raceDifficulty = min(max(t.raceDifficulty+gamepkg.Difficulty(delta), gamepkg.LevelTutorial), gamepkg.LevelSugoi)
+		},
+		help: lang.T("1P: WASD, Space\n2P: Arrow keys, Enter"),
+	})
+	items = append(items, menuItem{
+		label: lang.Sprintf("Co-op: < %s >", lang.T(t.coopDifficulty.String())),
+		action: func(game GameContext) {
+			game.GoToCoop(t.coopDifficulty, rand.Uint64())
+		},
+		adjust: func(delta int) {
+			t.coopDifficulty = min(max(t.coopDifficulty+gamepkg.Difficulty(delta), gamepkg.LevelTutorial), gamepkg.LevelSugoi)
+		},
+		help: lang.T("1P: Arrow keys, WASD (Move)\n2P: Space (Switches), Enter (Doors)"),
+	})
+	items = append(items, menuItem{
+		label: lang.T("Leaderboard"),
+		action: func(game GameContext) {
+			game.GoToLeaderboard()
+		},
+	})
+	items = append(items, menuItem{
+		label: lang.T("Settings"),
+		action: func(game GameContext) {
+			game.PushScene(&SettingsScene{})
+		},
+	})
+	if t.serverAddr != "" {
+		items = append(items, menuItem{
+			label: lang.Sprintf("Online Race (%s)", t.serverAddr),
+			action: func(game GameContext) {
+				game.GoToNetRace()
+			},
+		})
 	}
+	return items
+}
 
-	if inpututil.IsKeyJustPressed(ebiten.KeySpace) || inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
-		game.GoToGame(gamepkg.Difficulty(t.cursorIndex))
+func (t *TitleScene) Update(game GameContext) error {
+	if !t.inited {
+		if err := game.PlayBGM("title"); err != nil {
+			return err
+		}
+		s, err := LoadSaveData()
+		if err != nil {
+			return err
+		}
+		t.saveData = s
+		t.raceDifficulty = gamepkg.LevelNormal
+		t.coopDifficulty = gamepkg.LevelNormal
+		t.serverAddr = game.ServerAddr()
+		t.inited = true
 	}
+	t.attract.update(game)
+	t.menu.update(game, t.menuItems())
 	return nil
 }
 
 func (t *TitleScene) Draw(screen *ebiten.Image) {
-	msg := "The Sugoi Maze Building\n\n"
-	for i, difficulty := range []gamepkg.Difficulty{gamepkg.LevelTutorial, gamepkg.LevelEasy, gamepkg.LevelNormal, gamepkg.LevelHard, gamepkg.LevelSugoi} {
-		if i == t.cursorIndex {
-			msg += " -> "
-		} else {
-			msg += "    "
-		}
-		msg += difficulty.String() + "\n"
+	t.attract.draw(screen)
+	msg := lang.T("The Sugoi Maze Building") + "\n\n"
+	items := t.menuItems()
+	msg += t.menu.text(items)
+	help := t.menu.help(items)
+	if help == "" {
+		help = lang.T(singlePlayerHelp)
 	}
-	msg += `
-Controls:
-  - Arrow keys, WASD: Move
-  - Space, Enter:     Toggle switches, etc.
-`
-	ebitenutil.DebugPrint(screen, msg)
+	msg += "\n" + help
+	textutil.Print(screen, msg)
 }
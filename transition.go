@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2024 Hajime Hoshi
+
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+type transitionKind int
+
+const (
+	transitionFade transitionKind = iota
+	transitionWipe
+	transitionElevator
+)
+
+// transitionTicks is the duration of a transition. The outgoing scene is covered in the first half,
+// and the incoming scene is uncovered in the second half.
+const transitionTicks = 30
+
+var (
+	elevatorDoorColor     = color.RGBA{0x80, 0x88, 0x90, 0xff}
+	elevatorDoorEdgeColor = color.RGBA{0x40, 0x44, 0x48, 0xff}
+)
+
+// transition is an effect between two scenes. The scenes are not updated during a transition,
+// except that the incoming scene is updated once at the half-way point.
+type transition struct {
+	kind transitionKind
+	from []Scene
+	to   []Scene
+	tick int
+}
+
+// update advances the transition by one tick, and returns true when the transition finishes.
+func (t *transition) update() bool {
+	t.tick++
+	return t.tick >= transitionTicks
+}
+
+// isHalfway reports whether the incoming scene has just started being drawn.
+func (t *transition) isHalfway() bool {
+	return t.tick == transitionTicks/2
+}
+
+// rate returns how much the screen is covered, from 0 to 1.
+func (t *transition) rate() float64 {
+	const half = transitionTicks / 2
+	if t.tick < half {
+		return float64(t.tick) / half
+	}
+	return float64(transitionTicks-t.tick) / half
+}
+
+func (t *transition) draw(screen *ebiten.Image) {
+	covering := t.tick < transitionTicks/2
+	if covering {
+		drawScenes(screen, t.from)
+	} else {
+		drawScenes(screen, t.to)
+	}
+
+	r := t.rate()
+	b := screen.Bounds()
+	w, h := float32(b.Dx()), float32(b.Dy())
+	switch t.kind {
+	case transitionFade:
+		vector.DrawFilledRect(screen, 0, 0, w, h, color.RGBA{0, 0, 0, uint8(0xff * r)}, false)
+	case transitionWipe:
+		// The black band enters from the left and leaves to the right.
+		x := float32(0)
+		if !covering {
+			x = w * float32(1-r)
+		}
+		vector.DrawFilledRect(screen, x, 0, w*float32(r), h, color.Black, false)
+	case transitionElevator:
+		dw := w / 2 * float32(r)
+		vector.DrawFilledRect(screen, 0, 0, dw, h, elevatorDoorColor, false)
+		vector.DrawFilledRect(screen, w-dw, 0, dw, h, elevatorDoorColor, false)
+		vector.StrokeLine(screen, dw, 0, dw, h, 2, elevatorDoorEdgeColor, false)
+		vector.StrokeLine(screen, w-dw, 0, w-dw, h, 2, elevatorDoorEdgeColor, false)
+	}
+}